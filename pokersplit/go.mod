@@ -5,4 +5,5 @@ go 1.16
 require (
 	github.com/google/go-cmp v0.5.5
 	golang.org/x/text v0.3.6
+	rsc.io/qr v0.2.0
 )
@@ -1,22 +1,104 @@
 // Program pokersplit is a web application to register cash game poker buy-ins
 // and calculate who owns how much to whom at the end of the game.
+//
+// Run as "pokersplit net URL...", it prints the debts which remain unpaid
+// across the games whose URLs are given, netted and settled at once.
 package main
 
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
 
+	"github.com/fhchstr/pokersplit/pokersplit/players"
 	"github.com/fhchstr/pokersplit/pokersplit/pokersplit"
+	"golang.org/x/text/language"
 )
 
 var (
-	port = flag.Int("port", 8080, "TCP port to listen on")
+	port    = flag.Int("port", 8080, "TCP port to listen on")
+	rows    = flag.Int("rows", pokersplit.Rows, "number of blank rows of the form of a new game")
+	presets = flag.String("presets", "", "JSON file of the presets new games can be started from by name")
+	proxies = flag.String("trusted-proxies", "", "comma-separated IP addresses of the reverse proxies whose X-Forwarded-For header is trusted")
 )
 
 func main() {
 	flag.Parse()
+	if flag.Arg(0) == "net" {
+		if err := runNet(os.Stdout, flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *rows < 0 {
+		log.Fatalf("the number of blank rows must not be negative: %d", *rows)
+	}
+	pokersplit.Rows = *rows
+	for _, proxy := range strings.Split(*proxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			pokersplit.TrustedProxies = append(pokersplit.TrustedProxies, proxy)
+		}
+	}
+	if *presets != "" {
+		f, err := os.Open(*presets)
+		if err != nil {
+			log.Fatal(err)
+		}
+		pokersplit.Presets, err = players.ReadPresets(f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("failed to read presets from %s: %v", *presets, err)
+		}
+	}
 	http.HandleFunc("/", pokersplit.ServeHTTP)
+	http.HandleFunc("/history/", pokersplit.ServeHistory)
+	http.HandleFunc("/qr/", pokersplit.ServeQR)
+	http.HandleFunc("/g/", pokersplit.ServeShortURL)
+	http.HandleFunc("/net", pokersplit.ServeNet)
+	http.HandleFunc("/summary/", pokersplit.ServeSummary)
+	http.HandleFunc("/report/", pokersplit.ServeReport)
+	http.HandleFunc("/new", pokersplit.ServeNew)
+	http.HandleFunc("/static/", pokersplit.ServeStatic)
+	for _, path := range []string{"/manifest.webmanifest", "/sw.js", "/icon.svg"} {
+		http.HandleFunc(path, pokersplit.ServePWA)
+	}
 	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", *port), nil))
 }
+
+// runNet writes the debts netted across the games whose URLs are given.
+func runNet(w io.Writer, urls []string) error {
+	if len(urls) == 0 {
+		return fmt.Errorf("usage: pokersplit net URL...")
+	}
+	var games []players.Players
+	for _, u := range urls {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return fmt.Errorf("invalid URL %q: %v", u, err)
+		}
+		g, err := players.GameFromBase64(parsed.Path[strings.LastIndex(parsed.Path, "/")+1:])
+		if err != nil {
+			return fmt.Errorf("failed to decode game %q: %v", u, err)
+		}
+		games = append(games, g.Players)
+	}
+	p, err := players.Net(games)
+	if err != nil {
+		return err
+	}
+	debts, err := p.CalculateDebts()
+	if err != nil {
+		return fmt.Errorf("failed to calculate debts: %v", err)
+	}
+	for _, debtor := range debts.Debtors(language.English) {
+		for _, d := range debts[debtor] {
+			fmt.Fprintf(w, "%s owes %s to %s\n", debtor, d.Amount, d.Creditor)
+		}
+	}
+	return nil
+}
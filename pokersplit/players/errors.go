@@ -0,0 +1,22 @@
+package players
+
+import (
+	"fmt"
+)
+
+// Error is an error whose message can be translated: its format is used as
+// key to look up the translation, which is then formatted using the same
+// arguments.
+type Error struct {
+	Format string
+	Args   []interface{}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf(e.Format, e.Args...)
+}
+
+// errorf returns an Error. Unlike fmt.Errorf(), it doesn't support the %w verb.
+func errorf(format string, args ...interface{}) error {
+	return &Error{Format: format, Args: args}
+}
@@ -0,0 +1,79 @@
+package players
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// Game holds the players of a game along with the data needed to track its
+// successive revisions.
+type Game struct {
+	// ID identifies the game across all its revisions.
+	ID string `json:"i,omitempty"`
+	// Revision is incremented every time the game is saved.
+	Revision int `json:"r,omitempty"`
+	// Players taking part in the game.
+	Players Players `json:"p,omitempty"`
+	// History lists the last MaxHistory edits made to the game, oldest first.
+	History []Edit `json:"h,omitempty"`
+	// Settings of the game, if any. The changes of the standard buy-in are
+	// recorded in the history, like the ones of the players.
+	Settings *Settings `json:"s,omitempty"`
+}
+
+// MaxHistory is the number of edits kept in the history of a game. The history
+// is encoded in the URL of the game, which must stay short enough to be shared,
+// e.g. as a QR code: older edits are dropped, and can't be undone or restored
+// anymore.
+const MaxHistory = 20
+
+// Record appends the edit to the history of the game, dropping the oldest
+// edits beyond MaxHistory.
+func (g *Game) Record(e Edit) {
+	g.History = append(g.History, e)
+	if n := len(g.History) - MaxHistory; n > 0 {
+		g.History = append([]Edit(nil), g.History[n:]...)
+	}
+}
+
+// NewGame returns an empty game with a random ID.
+func NewGame() (*Game, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, errorf("failed to generate game ID: %v", err)
+	}
+	return &Game{ID: hex.EncodeToString(id)}, nil
+}
+
+// ToBase64 encodes the Game in base64 URL-encoding. It can be decoded using GameFromBase64().
+func (g *Game) ToBase64() (string, error) {
+	return encode(g)
+}
+
+// GameFromBase64 decodes a Game which was base64 URL-encoded using
+// Game.ToBase64(). For backward compatibility, it also accepts Players encoded
+// using Players.ToBase64(), in which case the returned Game has no ID and a
+// revision of zero.
+func GameFromBase64(data string) (*Game, error) {
+	ret := &Game{}
+	if data == "" {
+		return ret, nil
+	}
+
+	var raw json.RawMessage
+	if err := decode(data, &raw); err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("[")) {
+		if err := json.Unmarshal(raw, &ret.Players); err != nil {
+			return nil, errorf("failed to decode JSON: %v", err)
+		}
+		return ret, nil
+	}
+	if err := json.Unmarshal(raw, ret); err != nil {
+		return nil, errorf("failed to decode JSON: %v", err)
+	}
+	return ret, nil
+}
@@ -0,0 +1,101 @@
+package players
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGameBase64(t *testing.T) {
+	cases := []struct {
+		name string
+		game *Game
+	}{
+		{
+			name: "empty",
+			game: &Game{},
+		},
+		{
+			name: "id_and_revision_only",
+			game: &Game{ID: "0123456789abcdef", Revision: 3},
+		},
+		{
+			name: "all_fields",
+			game: &Game{
+				ID:       "0123456789abcdef",
+				Revision: 12,
+				Players:  Players{{Name: "Alice", BuyIn: Cents(100), Stack: Cents(8575)}, {Name: "Bob"}},
+				Settings: &Settings{BuyIn: Cents(2000), Currency: "CHF", Chips: []Money{Cents(50), Cents(100)}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b64, err := c.game.ToBase64()
+			if err != nil {
+				t.Fatalf("Game.ToBase64() returned an error: %v", err)
+			}
+			got, err := GameFromBase64(b64)
+			if err != nil {
+				t.Fatalf("GameFromBase64() return an error: %v", err)
+			}
+			if diff := cmp.Diff(c.game, got); diff != "" {
+				t.Errorf("base64 encoding/decoding mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestGameFromBase64Players tests that Players encoded before Game was
+// introduced can still be decoded.
+func TestGameFromBase64Players(t *testing.T) {
+	p := Players{{Name: "Alice", BuyIn: Cents(100), Stack: Cents(8575)}, {Name: "Bob"}}
+	b64, err := p.ToBase64()
+	if err != nil {
+		t.Fatalf("Players.ToBase64() returned an error: %v", err)
+	}
+	got, err := GameFromBase64(b64)
+	if err != nil {
+		t.Fatalf("GameFromBase64() return an error: %v", err)
+	}
+	if diff := cmp.Diff(&Game{Players: p}, got); diff != "" {
+		t.Errorf("GameFromBase64() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNewGame(t *testing.T) {
+	g1, err := NewGame()
+	if err != nil {
+		t.Fatalf("NewGame() returned an error: %v", err)
+	}
+	g2, err := NewGame()
+	if err != nil {
+		t.Fatalf("NewGame() returned an error: %v", err)
+	}
+	if g1.ID == "" || g1.ID == g2.ID {
+		t.Errorf("NewGame() returned games with IDs %q and %q, want distinct non-empty IDs", g1.ID, g2.ID)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	g := &Game{}
+	for r := 1; r <= MaxHistory+5; r++ {
+		g.Record(Edit{Revision: r, Parent: r - 1})
+	}
+	if len(g.History) != MaxHistory {
+		t.Fatalf("Game.Record() kept %d edits, want %d", len(g.History), MaxHistory)
+	}
+	if first, last := g.History[0].Revision, g.History[MaxHistory-1].Revision; first != 6 || last != MaxHistory+5 {
+		t.Errorf("Game.Record() kept revisions %d to %d, want 6 to %d", first, last, MaxHistory+5)
+	}
+	// The revisions older than the parent of the oldest kept edit can't be
+	// restored anymore.
+	g.Revision = MaxHistory + 5
+	if _, _, err := g.At(5); err != nil {
+		t.Errorf("Game.At(5) returned an error: %v", err)
+	}
+	if _, _, err := g.At(4); err == nil {
+		t.Errorf("Game.At(4) didn't return an error, but one was expected")
+	}
+}
@@ -0,0 +1,396 @@
+package players
+
+import (
+	"strings"
+	"time"
+)
+
+// Names of the fields which can be modified by a Change.
+const (
+	// FieldPlayer is used when a player is added to or removed from the game.
+	// Its old value is the name of the removed player, its new value the name
+	// of the added player.
+	FieldPlayer = "player"
+	// FieldName is used when a player is renamed. The name of the player
+	// recorded in the Change is the old one.
+	FieldName = "name"
+	// FieldBuyIn is used when the buy-in of a player is modified.
+	FieldBuyIn = "buyin"
+	// FieldStack is used when the stack of a player is modified.
+	FieldStack = "stack"
+	// FieldMethods is used when the payment methods of a player are modified.
+	// The values are comma-separated lists of methods.
+	FieldMethods = "methods"
+	// FieldAvoids is used when a player starts or stops avoiding to settle
+	// debts with another player. Its old value is the name of the player who
+	// isn't avoided anymore, its new value the name of the newly avoided one.
+	FieldAvoids = "avoids"
+	// FieldPrefers is used like FieldAvoids, when a player starts or stops
+	// preferring to settle debts with another player.
+	FieldPrefers = "prefers"
+	// FieldIBAN is used when the IBAN of a player is modified.
+	FieldIBAN = "iban"
+	// FieldAddress is used when the address of a player is modified.
+	FieldAddress = "address"
+	// FieldPaid is used like FieldAvoids, when a player marks a debt as paid
+	// or unpaid. The values are formatted like "bob: 12.50".
+	FieldPaid = "paid"
+	// FieldStandardBuyIn is used when the standard buy-in of the game is
+	// modified. The Change has no player, and an unset buy-in is recorded as
+	// an empty value.
+	FieldStandardBuyIn = "standardbuyin"
+)
+
+// Change describes the modification of a single field of a player or of the
+// settings of the game.
+type Change struct {
+	// Player is the name of the player whose field was modified, or empty if
+	// the settings of the game were modified.
+	Player string `json:"p"`
+	// Field is the name of the modified field.
+	Field string `json:"f"`
+	// Old and New are the values of the field before and after the change.
+	// Amounts are formatted in full currency units, e.g. "12.50".
+	Old string `json:"o,omitempty"`
+	New string `json:"n,omitempty"`
+}
+
+// Edit records the changes made when saving a revision of a game. The history
+// is advisory only: it is stored in the URL of the game, which anyone holding
+// it can modify.
+type Edit struct {
+	// Revision is the revision of the game created by the edit.
+	Revision int `json:"r"`
+	// Time at which the edit was saved.
+	Time time.Time `json:"t"`
+	// Editor is the name the person who made the edit gave or, if unknown,
+	// their anonymized IP address. It isn't authenticated.
+	Editor string `json:"e,omitempty"`
+	// Parent is the revision restored when undoing the edit.
+	Parent int `json:"a"`
+	// Undoes is the revision which was undone by the edit, if any. It is
+	// restored when redoing the edit.
+	Undoes int `json:"u,omitempty"`
+	// Changes made by the edit.
+	Changes []Change `json:"c"`
+}
+
+// Diff returns the changes needed to turn before into after. Players are
+// matched by name.
+func Diff(before, after Players) []Change {
+	var ret []Change
+	afterByName := make(map[string]*Player)
+	for _, p := range after {
+		afterByName[p.Name] = p
+	}
+	beforeByName := make(map[string]*Player)
+	for _, p := range before {
+		beforeByName[p.Name] = p
+	}
+
+	fields := func(b, a *Player) {
+		if !b.BuyIn.Equal(a.BuyIn) {
+			ret = append(ret, Change{Player: b.Name, Field: FieldBuyIn, Old: b.BuyIn.String(), New: a.BuyIn.String()})
+		}
+		if !b.Stack.Equal(a.Stack) {
+			ret = append(ret, Change{Player: b.Name, Field: FieldStack, Old: b.Stack.String(), New: a.Stack.String()})
+		}
+		if bMethods, aMethods := strings.Join(b.Methods, ","), strings.Join(a.Methods, ","); bMethods != aMethods {
+			ret = append(ret, Change{Player: b.Name, Field: FieldMethods, Old: bMethods, New: aMethods})
+		}
+		if b.IBAN != a.IBAN {
+			ret = append(ret, Change{Player: b.Name, Field: FieldIBAN, Old: b.IBAN, New: a.IBAN})
+		}
+		if b.Address != a.Address {
+			ret = append(ret, Change{Player: b.Name, Field: FieldAddress, Old: b.Address, New: a.Address})
+		}
+		for _, field := range []string{FieldAvoids, FieldPrefers, FieldPaid} {
+			bValues, aValues := *b.list(field), *a.list(field)
+			for _, v := range bValues {
+				if !contains(aValues, v) {
+					ret = append(ret, Change{Player: b.Name, Field: field, Old: v})
+				}
+			}
+			for _, v := range aValues {
+				if !contains(bValues, v) {
+					ret = append(ret, Change{Player: b.Name, Field: field, New: v})
+				}
+			}
+		}
+	}
+	for _, b := range before {
+		a, ok := afterByName[b.Name]
+		if ok {
+			fields(b, a)
+			continue
+		}
+		fields(b, &Player{Name: b.Name})
+		ret = append(ret, Change{Player: b.Name, Field: FieldPlayer, Old: b.Name})
+	}
+	for _, a := range after {
+		if _, ok := beforeByName[a.Name]; ok {
+			continue
+		}
+		ret = append(ret, Change{Player: a.Name, Field: FieldPlayer, New: a.Name})
+		fields(&Player{Name: a.Name}, a)
+	}
+	return ret
+}
+
+// DiffSettings returns the changes needed to turn the settings before into
+// the ones after. Both may be nil. Only the standard buy-in can be modified
+// once the game started.
+func DiffSettings(before, after *Settings) []Change {
+	b, a := before.buyIn(), after.buyIn()
+	if b.Equal(a) {
+		return nil
+	}
+	format := func(m Money) string {
+		if m.IsZero() {
+			return ""
+		}
+		return m.String()
+	}
+	return []Change{{Field: FieldStandardBuyIn, Old: format(b), New: format(a)}}
+}
+
+// buyIn returns the standard buy-in, which is zero if the settings are nil.
+func (s *Settings) buyIn() Money {
+	if s == nil {
+		return Money{}
+	}
+	return s.BuyIn
+}
+
+// Clone returns a deep copy of the Players.
+func (p Players) Clone() Players {
+	var ret Players
+	for _, player := range p {
+		clone := *player
+		clone.Methods = append([]string(nil), player.Methods...)
+		clone.Avoids = append([]string(nil), player.Avoids...)
+		clone.Prefers = append([]string(nil), player.Prefers...)
+		clone.Paid = append([]string(nil), player.Paid...)
+		ret = append(ret, &clone)
+	}
+	return ret
+}
+
+// revert undoes the changes on the players, in reverse order. The changes of
+// the settings are ignored.
+func (p Players) revert(changes []Change) (Players, error) {
+	ret := p.Clone()
+	find := func(name string) (int, error) {
+		if i := ret.find(name); i >= 0 {
+			return i, nil
+		}
+		return -1, errorf("player %q not found", name)
+	}
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		switch c.Field {
+		case FieldPlayer:
+			if c.Old != "" {
+				ret = append(ret, &Player{Name: c.Old})
+				continue
+			}
+			j, err := find(c.New)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret[:j], ret[j+1:]...)
+		case FieldName:
+			if _, err := find(c.New); err != nil {
+				return nil, err
+			}
+			ret.rename(c.New, c.Old)
+		case FieldBuyIn, FieldStack:
+			j, err := find(c.Player)
+			if err != nil {
+				return nil, err
+			}
+			amount, err := ParseMoney(c.Old)
+			if err != nil {
+				return nil, err
+			}
+			if c.Field == FieldBuyIn {
+				ret[j].BuyIn = amount
+			} else {
+				ret[j].Stack = amount
+			}
+		case FieldMethods:
+			j, err := find(c.Player)
+			if err != nil {
+				return nil, err
+			}
+			ret[j].Methods = nil
+			if c.Old != "" {
+				ret[j].Methods = strings.Split(c.Old, ",")
+			}
+		case FieldIBAN, FieldAddress:
+			j, err := find(c.Player)
+			if err != nil {
+				return nil, err
+			}
+			if c.Field == FieldIBAN {
+				ret[j].IBAN = c.Old
+			} else {
+				ret[j].Address = c.Old
+			}
+		case FieldAvoids, FieldPrefers, FieldPaid:
+			j, err := find(c.Player)
+			if err != nil {
+				return nil, err
+			}
+			values := ret[j].list(c.Field)
+			if c.New != "" {
+				*values = remove(*values, c.New)
+			}
+			if c.Old != "" {
+				*values = append(*values, c.Old)
+			}
+		case FieldStandardBuyIn:
+			continue
+		default:
+			return nil, errorf("unknown field %q", c.Field)
+		}
+	}
+	return ret, nil
+}
+
+// revert undoes the changes on the settings, which may be nil, in reverse
+// order. The changes of the players are ignored. The returned settings are
+// nil if none of them is set.
+func (s *Settings) revert(changes []Change) (*Settings, error) {
+	var ret Settings
+	if s != nil {
+		ret = *s
+		ret.Chips = append([]Money(nil), s.Chips...)
+	}
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		if c.Field != FieldStandardBuyIn {
+			continue
+		}
+		ret.BuyIn = Money{}
+		if c.Old != "" {
+			amount, err := ParseMoney(c.Old)
+			if err != nil {
+				return nil, err
+			}
+			ret.BuyIn = amount
+		}
+	}
+	if ret.IsZero() {
+		return nil, nil
+	}
+	return &ret, nil
+}
+
+// edit returns the edit which created the given revision, or nil if it isn't
+// part of the history.
+func (g *Game) edit(revision int) *Edit {
+	for i := range g.History {
+		if g.History[i].Revision == revision {
+			return &g.History[i]
+		}
+	}
+	return nil
+}
+
+// At returns the players and the settings as they were at the given revision.
+// Older revisions are reconstructed by reverting the edits recorded in the
+// history.
+func (g *Game) At(revision int) (Players, *Settings, error) {
+	if revision < 0 || revision > g.Revision {
+		return nil, nil, errorf("revision %d doesn't exist", revision)
+	}
+	p, s := g.Players.Clone(), g.Settings
+	for r := g.Revision; r > revision; r-- {
+		e := g.edit(r)
+		if e == nil {
+			return nil, nil, errorf("revision %d is missing from the history", r)
+		}
+		var err error
+		if p, err = p.revert(e.Changes); err != nil {
+			return nil, nil, errorf("failed to revert revision %d: %v", r, err)
+		}
+		if s, err = s.revert(e.Changes); err != nil {
+			return nil, nil, errorf("failed to revert revision %d: %v", r, err)
+		}
+	}
+	return p, s, nil
+}
+
+// Parent returns the revision restored when undoing the given revision.
+func (g *Game) Parent(revision int) (int, bool) {
+	if revision == 0 {
+		return 0, false
+	}
+	e := g.edit(revision)
+	if e == nil {
+		return 0, false
+	}
+	return e.Parent, true
+}
+
+// CanUndo returns whether the current revision can be undone.
+func (g *Game) CanUndo() bool {
+	_, _, _, err := g.Undo()
+	return err == nil
+}
+
+// CanRedo returns whether the current revision undid another one, which can
+// be restored.
+func (g *Game) CanRedo() bool {
+	e := g.edit(g.Revision)
+	return e != nil && e.Undoes > 0
+}
+
+// Undo returns the players and the settings as they were before the current
+// revision, along with an Edit which has its Parent and Undoes fields set
+// accordingly. The other fields are left to the caller.
+func (g *Game) Undo() (Players, *Settings, Edit, error) {
+	target, ok := g.Parent(g.Revision)
+	if !ok {
+		return nil, nil, Edit{}, errorf("revision %d can't be undone", g.Revision)
+	}
+	p, s, err := g.At(target)
+	if err != nil {
+		return nil, nil, Edit{}, errorf("failed to undo revision %d: %v", g.Revision, err)
+	}
+	parent, _ := g.Parent(target)
+	return p, s, Edit{Parent: parent, Undoes: g.Revision}, nil
+}
+
+// Redo returns the players and the settings as they were at the revision
+// undone by the current revision, along with an Edit which has its Parent and
+// Undoes fields set accordingly. The other fields are left to the caller.
+func (g *Game) Redo() (Players, *Settings, Edit, error) {
+	if !g.CanRedo() {
+		return nil, nil, Edit{}, errorf("there is nothing to redo")
+	}
+	target := g.edit(g.Revision).Undoes
+	p, s, err := g.At(target)
+	if err != nil {
+		return nil, nil, Edit{}, errorf("failed to redo revision %d: %v", target, err)
+	}
+	e := g.edit(target)
+	if e == nil {
+		return nil, nil, Edit{}, errorf("revision %d is missing from the history", target)
+	}
+	// Redoing restores the target revision, including its own undo/redo links.
+	return p, s, Edit{Parent: e.Parent, Undoes: e.Undoes}, nil
+}
+
+// Restore returns the players and the settings as they were at the given
+// revision, along with an Edit which has its Parent and Undoes fields set
+// accordingly. The other fields are left to the caller. Unlike Undo(), undoing
+// a restoration goes back to the current revision.
+func (g *Game) Restore(revision int) (Players, *Settings, Edit, error) {
+	p, s, err := g.At(revision)
+	if err != nil {
+		return nil, nil, Edit{}, errorf("failed to restore revision %d: %v", revision, err)
+	}
+	return p, s, Edit{Parent: g.Revision}, nil
+}
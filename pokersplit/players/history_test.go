@@ -0,0 +1,240 @@
+package players
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestDiff(t *testing.T) {
+	cases := []struct {
+		desc   string
+		before Players
+		after  Players
+		want   []Change
+	}{
+		{
+			desc: "no_players",
+		},
+		{
+			desc:   "no_changes",
+			before: Players{{Name: "alice", BuyIn: Cents(1000), Stack: Cents(500)}},
+			after:  Players{{Name: "alice", BuyIn: Cents(1000), Stack: Cents(500)}},
+		},
+		{
+			desc:   "buyin_and_stack_changed",
+			before: Players{{Name: "alice", BuyIn: Cents(1000)}, {Name: "bob", BuyIn: Cents(1000)}},
+			after:  Players{{Name: "alice", BuyIn: Cents(2000)}, {Name: "bob", BuyIn: Cents(1000), Stack: Cents(1250)}},
+			want: []Change{
+				{Player: "alice", Field: FieldBuyIn, Old: "10.00", New: "20.00"},
+				{Player: "bob", Field: FieldStack, Old: "0.00", New: "12.50"},
+			},
+		},
+		{
+			desc:   "player_added",
+			before: Players{{Name: "alice", BuyIn: Cents(1000)}},
+			after:  Players{{Name: "alice", BuyIn: Cents(1000)}, {Name: "bob", BuyIn: Cents(505)}},
+			want: []Change{
+				{Player: "bob", Field: FieldPlayer, New: "bob"},
+				{Player: "bob", Field: FieldBuyIn, Old: "0.00", New: "5.05"},
+			},
+		},
+		{
+			desc:   "methods_changed",
+			before: Players{{Name: "alice"}, {Name: "bob", Methods: []string{MethodCash}}},
+			after:  Players{{Name: "alice", Methods: []string{MethodCash, MethodMobile}}, {Name: "bob"}},
+			want: []Change{
+				{Player: "alice", Field: FieldMethods, New: "cash,mobile"},
+				{Player: "bob", Field: FieldMethods, Old: "cash"},
+			},
+		},
+		{
+			desc:   "constraints_changed",
+			before: Players{{Name: "alice", Avoids: []string{"bob"}}, {Name: "bob"}, {Name: "charlie"}},
+			after:  Players{{Name: "alice", Prefers: []string{"charlie"}}, {Name: "bob"}, {Name: "charlie"}},
+			want: []Change{
+				{Player: "alice", Field: FieldAvoids, Old: "bob"},
+				{Player: "alice", Field: FieldPrefers, New: "charlie"},
+			},
+		},
+		{
+			desc:   "paid_debts_changed",
+			before: Players{{Name: "alice", Paid: []string{"bob: 5.00"}}, {Name: "bob"}},
+			after:  Players{{Name: "alice", Paid: []string{"bob: 6.00"}}, {Name: "bob"}},
+			want: []Change{
+				{Player: "alice", Field: FieldPaid, Old: "bob: 5.00"},
+				{Player: "alice", Field: FieldPaid, New: "bob: 6.00"},
+			},
+		},
+		{
+			desc:   "bank_details_changed",
+			before: Players{{Name: "alice", IBAN: "CH9300762011623852957"}},
+			after:  Players{{Name: "alice", IBAN: "DE89370400440532013000", Address: "8000 Zürich"}},
+			want: []Change{
+				{Player: "alice", Field: FieldIBAN, Old: "CH9300762011623852957", New: "DE89370400440532013000"},
+				{Player: "alice", Field: FieldAddress, New: "8000 Zürich"},
+			},
+		},
+		{
+			desc:   "player_removed",
+			before: Players{{Name: "alice", BuyIn: Cents(1000)}, {Name: "bob", BuyIn: Cents(500), Stack: Cents(-1)}},
+			after:  Players{{Name: "alice", BuyIn: Cents(1000)}},
+			want: []Change{
+				{Player: "bob", Field: FieldBuyIn, Old: "5.00", New: "0.00"},
+				{Player: "bob", Field: FieldStack, Old: "-0.01", New: "0.00"},
+				{Player: "bob", Field: FieldPlayer, Old: "bob"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got := Diff(c.before, c.after)
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Diff() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// save appends a revision to the game, the same way the web server does.
+func save(g *Game, p Players, e Edit) {
+	e.Revision = g.Revision + 1
+	e.Changes = Diff(g.Players, p)
+	g.History = append(g.History, e)
+	g.Revision++
+	g.Players = p
+}
+
+func TestAt(t *testing.T) {
+	g := &Game{}
+	rev1 := Players{{Name: "alice", BuyIn: Cents(1000)}, {Name: "bob", BuyIn: Cents(1000)}}
+	rev2 := Players{{Name: "alice", BuyIn: Cents(2000)}, {Name: "bob", BuyIn: Cents(1000), Methods: []string{MethodCash}}, {Name: "charlie", BuyIn: Cents(500)}}
+	rev3 := Players{{Name: "alice", BuyIn: Cents(2000), Stack: Cents(3500)}, {Name: "charlie", BuyIn: Cents(500), Methods: []string{MethodMobile}}}
+	save(g, rev1, Edit{Parent: 0})
+	save(g, rev2, Edit{Parent: 1})
+	save(g, rev3, Edit{Parent: 2})
+
+	for revision, want := range []Players{nil, rev1, rev2, rev3} {
+		got, _, err := g.At(revision)
+		if err != nil {
+			t.Fatalf("Game.At(%d) returned an error: %v", revision, err)
+		}
+		if diff := cmp.Diff(want, got, sortPlayer, cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("Game.At(%d) mismatch (-want +got):\n%s", revision, diff)
+		}
+	}
+	if _, _, err := g.At(4); err == nil {
+		t.Errorf("Game.At(4) didn't return an error, but one was expected")
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	g := &Game{}
+	rev1 := Players{{Name: "alice", BuyIn: Cents(1000)}}
+	rev2 := Players{{Name: "alice", BuyIn: Cents(2000)}}
+	save(g, rev1, Edit{Parent: 0})
+	save(g, rev2, Edit{Parent: 1})
+
+	steps := []struct {
+		desc string
+		do   func() (Players, *Settings, Edit, error)
+		want Players
+	}{
+		{desc: "undo_rev2", do: g.Undo, want: rev1},
+		{desc: "undo_rev1", do: g.Undo, want: nil},
+		{desc: "redo_rev1", do: g.Redo, want: rev1},
+		{desc: "redo_rev2", do: g.Redo, want: rev2},
+		{desc: "undo_rev2_again", do: g.Undo, want: rev1},
+		{desc: "restore_rev2", do: func() (Players, *Settings, Edit, error) { return g.Restore(2) }, want: rev2},
+		{desc: "undo_restoration", do: g.Undo, want: rev1},
+		{desc: "redo_restoration", do: g.Redo, want: rev2},
+	}
+	for _, s := range steps {
+		p, _, e, err := s.do()
+		if err != nil {
+			t.Fatalf("%s: returned an error: %v", s.desc, err)
+		}
+		if diff := cmp.Diff(s.want, p, cmpopts.EquateEmpty()); diff != "" {
+			t.Fatalf("%s: mismatch (-want +got):\n%s", s.desc, diff)
+		}
+		save(g, p, e)
+	}
+	if g.CanRedo() {
+		t.Errorf("Game.CanRedo() = true after everything was redone, want false")
+	}
+	if _, _, _, err := (&Game{}).Undo(); err == nil {
+		t.Errorf("Game.Undo() of an empty game didn't return an error, but one was expected")
+	}
+}
+
+func TestAtRename(t *testing.T) {
+	g := &Game{}
+	rev1 := Players{{Name: "alcie", BuyIn: Cents(1000)}, {Name: "bob", Avoids: []string{"alcie"}}}
+	save(g, rev1, Edit{Parent: 0})
+	rev2, change, err := g.Players.Rename("alcie", "alice")
+	if err != nil {
+		t.Fatalf("Players.Rename() returned an error: %v", err)
+	}
+	g.History = append(g.History, Edit{Revision: 2, Parent: 1, Changes: []Change{change}})
+	g.Revision = 2
+	g.Players = rev2
+	save(g, Players{{Name: "alice", BuyIn: Cents(2000)}, {Name: "bob", Avoids: []string{"alice"}, Prefers: []string{"charlie"}}, {Name: "charlie"}}, Edit{Parent: 2})
+
+	got, _, err := g.At(1)
+	if err != nil {
+		t.Fatalf("Game.At(1) returned an error: %v", err)
+	}
+	if diff := cmp.Diff(rev1, got, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Game.At(1) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestDiffSettings(t *testing.T) {
+	cases := []struct {
+		desc          string
+		before, after *Settings
+		want          []Change
+	}{
+		{desc: "unchanged", before: &Settings{BuyIn: Cents(2000)}, after: &Settings{BuyIn: Cents(2000), Currency: "CHF"}},
+		{desc: "set", after: &Settings{BuyIn: Cents(2000)}, want: []Change{{Field: FieldStandardBuyIn, New: "20.00"}}},
+		{desc: "modified", before: &Settings{BuyIn: Cents(2000)}, after: &Settings{BuyIn: Cents(5000)}, want: []Change{{Field: FieldStandardBuyIn, Old: "20.00", New: "50.00"}}},
+		{desc: "unset", before: &Settings{BuyIn: Cents(2000)}, want: []Change{{Field: FieldStandardBuyIn, Old: "20.00"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			if diff := cmp.Diff(c.want, DiffSettings(c.before, c.after)); diff != "" {
+				t.Errorf("DiffSettings() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUndoSettings(t *testing.T) {
+	p := Players{{Name: "alice", BuyIn: Cents(1000)}}
+	g := &Game{Settings: &Settings{Currency: "CHF"}}
+	save(g, p, Edit{Parent: 0})
+	// Only the standard buy-in is modified.
+	g.History = append(g.History, Edit{Revision: 2, Parent: 1, Changes: DiffSettings(g.Settings, &Settings{BuyIn: Cents(2000), Currency: "CHF"})})
+	g.Revision = 2
+	g.Settings = &Settings{BuyIn: Cents(2000), Currency: "CHF"}
+
+	got, settings, _, err := g.Undo()
+	if err != nil {
+		t.Fatalf("Game.Undo() returned an error: %v", err)
+	}
+	if diff := cmp.Diff(p, got, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Game.Undo() players mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(&Settings{Currency: "CHF"}, settings, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Game.Undo() settings mismatch (-want +got):\n%s", diff)
+	}
+
+	_, settings, _, err = g.Restore(0)
+	if err != nil {
+		t.Fatalf("Game.Restore(0) returned an error: %v", err)
+	}
+	if diff := cmp.Diff(&Settings{Currency: "CHF"}, settings, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Game.Restore(0) settings mismatch (-want +got):\n%s", diff)
+	}
+}
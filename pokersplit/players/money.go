@@ -0,0 +1,194 @@
+package players
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount of money, in full currency units. Unlike floating
+// point numbers, it doesn't suffer from rounding errors and it isn't limited
+// to a given number of decimals. The zero value is zero.
+//
+// Money is immutable: its methods return new values. It can't be compared
+// using ==, Equal() and Cmp() must be used instead.
+type Money struct {
+	r *big.Rat
+	_ [0]func()
+}
+
+// Cents returns the given amount of cents as Money.
+func Cents(cents int) Money {
+	return Money{r: big.NewRat(int64(cents), 100)}
+}
+
+// ParseMoney parses an amount formatted by String(), e.g. "12.50" or "-3".
+// Group separators and exponents aren't allowed.
+func ParseMoney(s string) (Money, error) {
+	digits := strings.TrimPrefix(s, "-")
+	units, decimals := digits, ""
+	if i := strings.Index(digits, "."); i >= 0 {
+		units, decimals = digits[:i], digits[i+1:]
+	}
+	if units == "" || !isDigits(units) || !isDigits(decimals) {
+		return Money{}, errorf("invalid amount %q", s)
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return Money{}, errorf("invalid amount %q", s)
+	}
+	return Money{r: r}, nil
+}
+
+// rat returns the amount as a big.Rat, which must not be modified.
+func (m Money) rat() *big.Rat {
+	if m.r == nil {
+		return new(big.Rat)
+	}
+	return m.r
+}
+
+// Add returns m+o.
+func (m Money) Add(o Money) Money {
+	return Money{r: new(big.Rat).Add(m.rat(), o.rat())}
+}
+
+// Sub returns m-o.
+func (m Money) Sub(o Money) Money {
+	return Money{r: new(big.Rat).Sub(m.rat(), o.rat())}
+}
+
+// Neg returns -m.
+func (m Money) Neg() Money {
+	return Money{r: new(big.Rat).Neg(m.rat())}
+}
+
+// Floor returns the largest multiple of the unit which is less than or equal
+// to m. The unit must be positive.
+func (m Money) Floor(unit Money) Money {
+	q := new(big.Rat).Quo(m.rat(), unit.rat())
+	// The denominator is always positive, the Euclidean division thus rounds
+	// towards negative infinity.
+	n := new(big.Int).Div(q.Num(), q.Denom())
+	return Money{r: new(big.Rat).Mul(new(big.Rat).SetInt(n), unit.rat())}
+}
+
+// Cmp compares m and o, and returns -1 if m < o, 0 if m == o and +1 if m > o.
+func (m Money) Cmp(o Money) int {
+	return m.rat().Cmp(o.rat())
+}
+
+// Sign returns -1 if m < 0, 0 if m == 0 and +1 if m > 0.
+func (m Money) Sign() int {
+	return m.rat().Sign()
+}
+
+// IsZero returns whether m is zero.
+func (m Money) IsZero() bool {
+	return m.Sign() == 0
+}
+
+// Equal returns whether m and o are the same amount.
+func (m Money) Equal(o Money) bool {
+	return m.Cmp(o) == 0
+}
+
+// Float64 returns the nearest float64 value of the amount, for computations
+// which don't need to be exact, e.g. percentages.
+func (m Money) Float64() float64 {
+	f, _ := m.rat().Float64()
+	return f
+}
+
+// maxStringDecimals is the number of decimals amounts which can't be
+// represented exactly in decimal are rounded to.
+const maxStringDecimals = 20
+
+// decimals returns the number of decimals needed to represent the amount
+// exactly, but at least min.
+func (m Money) decimals(min int) int {
+	r := m.rat()
+	if r.IsInt() {
+		return min
+	}
+	d := new(big.Rat).Set(r)
+	ten := big.NewRat(10, 1)
+	n := 0
+	for ; n < maxStringDecimals && !d.IsInt(); n++ {
+		d.Mul(d, ten)
+	}
+	if n < min {
+		return min
+	}
+	return n
+}
+
+// String formats the amount with at least 2 decimals and as many as needed
+// to represent it exactly, e.g. "12.50" or "0.125".
+func (m Money) String() string {
+	return m.Text(".", "")
+}
+
+// Text formats the amount like String(), but using the given decimal
+// separator and grouping the digits by three using the given group
+// separator.
+func (m Money) Text(decimalSep, groupSep string) string {
+	s := m.rat().FloatString(m.decimals(2))
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	units, decimals := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		units, decimals = s[:i], s[i+1:]
+	}
+	if groupSep != "" {
+		var groups []string
+		for len(units) > 3 {
+			groups = append([]string{units[len(units)-3:]}, groups...)
+			units = units[:len(units)-3]
+		}
+		units = strings.Join(append([]string{units}, groups...), groupSep)
+	}
+	return sign + units + decimalSep + decimals
+}
+
+// MarshalJSON encodes the amount as a string, with as few decimals as
+// possible to keep the URLs short. Zero is encoded as the number 0.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if m.IsZero() {
+		return []byte("0"), nil
+	}
+	s := m.rat().FloatString(m.decimals(0))
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(s, "0")
+	}
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON decodes an amount encoded by MarshalJSON(). For backward
+// compatibility, numbers are amounts of cents.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	if strings.HasPrefix(string(data), `"`) {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		parsed, err := ParseMoney(s)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+	cents, err := strconv.Atoi(string(data))
+	if err != nil {
+		return errorf("invalid amount %q: %v", string(data), err)
+	}
+	*m = Cents(cents)
+	return nil
+}
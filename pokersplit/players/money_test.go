@@ -0,0 +1,165 @@
+package players
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// mustParseMoney parses the amount and panics if it is invalid.
+func mustParseMoney(s string) Money {
+	m, err := ParseMoney(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func TestParseMoney(t *testing.T) {
+	cases := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{input: "0", want: Money{}},
+		{input: "12", want: Cents(1200)},
+		{input: "12.5", want: Cents(1250)},
+		{input: "-0.01", want: Cents(-1)},
+		{input: "0.001", want: Cents(1).Sub(mustParseMoney("0.009"))},
+		{input: "", wantErr: true},
+		{input: ".5", wantErr: true},
+		{input: "1e3", wantErr: true},
+		{input: "1/3", wantErr: true},
+		{input: "1,000", wantErr: true},
+		{input: "--1", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			got, err := ParseMoney(c.input)
+			if err != nil && !c.wantErr {
+				t.Fatalf("ParseMoney(%q) returned an error: %v", c.input, err)
+			}
+			if err == nil && c.wantErr {
+				t.Fatalf("ParseMoney(%q) = %v, but an error was expected", c.input, got)
+			}
+			if !got.Equal(c.want) {
+				t.Errorf("ParseMoney(%q) = %v, want %v", c.input, got, c.want)
+			}
+		})
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	// 0.1 + 0.2 isn't 0.3 using floating point numbers.
+	got := mustParseMoney("0.1").Add(mustParseMoney("0.2"))
+	if want := mustParseMoney("0.3"); !got.Equal(want) {
+		t.Errorf("0.1 + 0.2 = %v, want %v", got, want)
+	}
+	if got := Cents(1000).Sub(Cents(1250)); got.Cmp(Money{}) >= 0 || got.Sign() != -1 {
+		t.Errorf("10.00 - 12.50 = %v, want a negative amount", got)
+	}
+	if got := Cents(1250).Neg().Add(Cents(1250)); !got.IsZero() {
+		t.Errorf("-12.50 + 12.50 = %v, want 0", got)
+	}
+}
+
+func TestMoneyFloor(t *testing.T) {
+	cases := []struct {
+		amount Money
+		unit   Money
+		want   Money
+	}{
+		{amount: Cents(1337), unit: Cents(50), want: Cents(1300)},
+		{amount: Cents(1350), unit: Cents(50), want: Cents(1350)},
+		{amount: Cents(-637), unit: Cents(50), want: Cents(-650)},
+		{amount: Cents(-600), unit: Cents(500), want: Cents(-1000)},
+		{amount: Cents(499), unit: Cents(500), want: Money{}},
+	}
+	for _, c := range cases {
+		if got := c.amount.Floor(c.unit); !got.Equal(c.want) {
+			t.Errorf("(%v).Floor(%v) = %v, want %v", c.amount, c.unit, got, c.want)
+		}
+	}
+}
+
+func TestMoneyText(t *testing.T) {
+	cases := []struct {
+		amount     Money
+		decimalSep string
+		groupSep   string
+		want       string
+	}{
+		{amount: Money{}, decimalSep: ".", want: "0.00"},
+		{amount: Cents(1250), decimalSep: ".", want: "12.50"},
+		{amount: Cents(-5), decimalSep: ".", want: "-0.05"},
+		{amount: mustParseMoney("0.125"), decimalSep: ".", want: "0.125"},
+		{amount: Cents(123456789), decimalSep: ".", groupSep: "'", want: "1'234'567.89"},
+		{amount: Cents(-100000), decimalSep: ",", groupSep: ".", want: "-1.000,00"},
+		{amount: Cents(99900), decimalSep: ",", groupSep: " ", want: "999,00"},
+	}
+	for _, c := range cases {
+		if got := c.amount.Text(c.decimalSep, c.groupSep); got != c.want {
+			t.Errorf("Money.Text(%q, %q) = %q, want %q", c.decimalSep, c.groupSep, got, c.want)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	cases := []struct {
+		desc   string
+		amount Money
+		want   string
+	}{
+		{desc: "zero", amount: Money{}, want: `0`},
+		{desc: "units", amount: Cents(1200), want: `"12"`},
+		{desc: "cents", amount: Cents(1250), want: `"12.5"`},
+		{desc: "fraction_of_cents", amount: mustParseMoney("-0.125"), want: `"-0.125"`},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			data, err := json.Marshal(c.amount)
+			if err != nil {
+				t.Fatalf("json.Marshal() returned an error: %v", err)
+			}
+			if string(data) != c.want {
+				t.Errorf("json.Marshal() = %s, want %s", data, c.want)
+			}
+			var got Money
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("json.Unmarshal() returned an error: %v", err)
+			}
+			if !got.Equal(c.amount) {
+				t.Errorf("json.Unmarshal(%s) = %v, want %v", data, got, c.amount)
+			}
+		})
+	}
+}
+
+// TestMoneyUnmarshalJSONLegacy tests that the amounts encoded in cents by
+// previous versions are still decoded.
+func TestMoneyUnmarshalJSONLegacy(t *testing.T) {
+	var got Player
+	if err := json.Unmarshal([]byte(`{"p":"alice","b":1250}`), &got); err != nil {
+		t.Fatalf("json.Unmarshal() returned an error: %v", err)
+	}
+	if !got.BuyIn.Equal(Cents(1250)) || !got.Stack.IsZero() {
+		t.Errorf("json.Unmarshal() = %+v, want a buy-in of 12.50 and no stack", got)
+	}
+	if err := json.Unmarshal([]byte(`{"b":12.5}`), &got); err == nil {
+		t.Errorf("json.Unmarshal() of a fractional amount of cents didn't return an error")
+	}
+}
+
+func TestFloat64(t *testing.T) {
+	for _, c := range []struct {
+		m    Money
+		want float64
+	}{
+		{m: Money{}, want: 0},
+		{m: Cents(1250), want: 12.5},
+		{m: Cents(-5), want: -0.05},
+	} {
+		if got := c.m.Float64(); got != c.want {
+			t.Errorf("Money(%v).Float64() = %v, want %v", c.m, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package players
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
+)
+
+var (
+	// keyCollator computes the keys used to compare players' names. Collators
+	// aren't safe for concurrent use, hence the mutex.
+	keyCollator   = NewCollator(language.English)
+	keyCollatorMu sync.Mutex
+	keyBuf        collate.Buffer
+)
+
+// NewCollator returns a collator comparing players' names for the given
+// language, ignoring case and accents.
+func NewCollator(tag language.Tag) *collate.Collator {
+	return collate.New(tag, collate.IgnoreCase, collate.IgnoreDiacritics)
+}
+
+// NormalizeName returns the name without leading, trailing and repeated
+// whitespaces, in Unicode normalization form C. It is otherwise left as typed.
+func NormalizeName(name string) string {
+	return norm.NFC.String(strings.Join(strings.Fields(name), " "))
+}
+
+// nameKey returns a key which is the same for all the variants of a name.
+func nameKey(name string) string {
+	keyCollatorMu.Lock()
+	defer keyCollatorMu.Unlock()
+	keyBuf.Reset()
+	return string(keyCollator.KeyFromString(&keyBuf, NormalizeName(name)))
+}
+
+// lessName reports whether the name a sorts before the name b, regardless of
+// the language. Variants of a name are sorted by their bytes, so that the
+// order is total.
+func lessName(a, b string) bool {
+	if ka, kb := nameKey(a), nameKey(b); ka != kb {
+		return ka < kb
+	}
+	return a < b
+}
+
+// normalizeNames returns the normalized names, ignoring the empty ones.
+func normalizeNames(names []string) []string {
+	var ret []string
+	for _, name := range names {
+		if name = NormalizeName(name); name != "" {
+			ret = append(ret, name)
+		}
+	}
+	return ret
+}
+
+// SplitNames splits a list of names separated by new lines, commas or
+// semicolons, e.g. pasted from a group chat. The names are normalized and the
+// empty ones are ignored.
+func SplitNames(list string) []string {
+	return normalizeNames(strings.FieldsFunc(list, func(r rune) bool {
+		return r == '\n' || r == '\r' || r == ',' || r == ';'
+	}))
+}
+
+// names keeps track of players' names to detect duplicates.
+type names map[string]string
+
+// add adds a name to the set. It returns an error if the name, or a variant
+// of it, was already part of it.
+func (n names) add(name string) error {
+	key := nameKey(name)
+	if existing, ok := n[key]; ok {
+		if existing == name {
+			return errorf("duplicate player with name %q", name)
+		}
+		return errorf("duplicate player with name %q, which is the same as %q", name, existing)
+	}
+	n[key] = name
+	return nil
+}
+
+// find returns the index of the player with the given name, or a variant of
+// it, or -1 if there is none.
+func (p Players) find(name string) int {
+	key := nameKey(name)
+	for i, player := range p {
+		if nameKey(player.Name) == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,114 @@
+package players
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNormalizeName(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{input: "Alice", want: "Alice"},
+		{input: "  alice ", want: "alice"},
+		{input: "Mary  \t Ann", want: "Mary Ann"},
+		// "e" followed by a combining acute accent is composed into "é".
+		{input: "Ame\u0301lie", want: "Am\u00e9lie"},
+	}
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			if got := NormalizeName(c.input); got != c.want {
+				t.Errorf("NormalizeName(%q) = %q, want %q", c.input, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSplitNames(t *testing.T) {
+	cases := []struct {
+		input string
+		want  []string
+	}{
+		{input: "", want: nil},
+		{input: "alice", want: []string{"alice"}},
+		{input: "alice\nbob\r\ncharlie", want: []string{"alice", "bob", "charlie"}},
+		{input: " alice , bob;charlie,, \n", want: []string{"alice", "bob", "charlie"}},
+		{input: "Mary  Ann, Ame\u0301lie", want: []string{"Mary Ann", "Am\u00e9lie"}},
+	}
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			if diff := cmp.Diff(c.want, SplitNames(c.input)); diff != "" {
+				t.Errorf("SplitNames(%q) mismatch (-want +got):\n%s", c.input, diff)
+			}
+		})
+	}
+}
+
+func TestNamesAdd(t *testing.T) {
+	cases := []struct {
+		desc    string
+		names   []string
+		wantErr bool
+	}{
+		{
+			desc:  "distinct",
+			names: []string{"Alice", "Bob", "Alicia"},
+		},
+		{
+			desc:    "identical",
+			names:   []string{"Alice", "Alice"},
+			wantErr: true,
+		},
+		{
+			desc:    "different_case",
+			names:   []string{"Alice", "alice"},
+			wantErr: true,
+		},
+		{
+			desc:    "different_accents",
+			names:   []string{"Alice", "Alíce"},
+			wantErr: true,
+		},
+		{
+			desc:    "different_whitespaces",
+			names:   []string{"Mary Ann", " mary  ann "},
+			wantErr: true,
+		},
+		{
+			desc:    "different_normalization_form",
+			names:   []string{"Am\u00e9lie", "Ame\u0301lie"},
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			n := make(names)
+			var err error
+			for _, name := range c.names {
+				if err = n.add(name); err != nil {
+					break
+				}
+			}
+			if err != nil && !c.wantErr {
+				t.Fatalf("names.add() returned an error: %v", err)
+			}
+			if err == nil && c.wantErr {
+				t.Fatalf("names.add() didn't return an error, but one was expected")
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	p := Players{{Name: "Alice"}, {Name: "Bob"}}
+	for _, name := range []string{"Alice", "alice", " ALÍCE"} {
+		if got := p.find(name); got != 0 {
+			t.Errorf("Players.find(%q) = %d, want 0", name, got)
+		}
+	}
+	if got := p.find("Charlie"); got != -1 {
+		t.Errorf("Players.find(%q) = %d, want -1", "Charlie", got)
+	}
+}
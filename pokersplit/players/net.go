@@ -0,0 +1,66 @@
+package players
+
+// Net merges the players of several games into a single set of players whose
+// balances are the debts they didn't pay yet, so that they can be settled all
+// at once. Players are matched by name, regardless of case, accents and
+// whitespaces; the name they first appear with is kept.
+//
+// The payment methods and the constraints of a player are the ones of the
+// last game they took part in, and so are their IBAN and address, unless they
+// didn't provide them. The debts of each game are calculated without
+// rounding, the debts marked as paid being ignored.
+func Net(games []Players) (Players, error) {
+	var ret Players
+	byKey := make(map[string]*Player)
+	balances := make(map[string]Money)
+	for i, p := range games {
+		debts, err := p.CalculateDebts()
+		if err != nil {
+			return nil, errorf("failed to calculate the debts of game %d: %v", i+1, err)
+		}
+		for _, player := range p {
+			key := nameKey(player.Name)
+			net, ok := byKey[key]
+			if !ok {
+				net = &Player{Name: player.Name}
+				byKey[key] = net
+				ret = append(ret, net)
+			}
+			net.Methods = append([]string(nil), player.Methods...)
+			net.Avoids = append([]string(nil), player.Avoids...)
+			net.Prefers = append([]string(nil), player.Prefers...)
+			if player.IBAN != "" {
+				net.IBAN, net.Address = player.IBAN, player.Address
+			}
+		}
+		for debtor, ds := range debts {
+			for _, d := range ds {
+				if d.Paid {
+					continue
+				}
+				balances[nameKey(debtor)] = balances[nameKey(debtor)].Sub(d.Amount)
+				balances[nameKey(d.Creditor)] = balances[nameKey(d.Creditor)].Add(d.Amount)
+			}
+		}
+	}
+	for key, player := range byKey {
+		if balance := balances[key]; balance.Sign() > 0 {
+			player.Stack = balance
+		} else {
+			player.BuyIn = balance.Neg()
+		}
+		// The constraints refer to the names used in the last game, which may
+		// be variants of the kept ones.
+		for _, field := range []string{FieldAvoids, FieldPrefers} {
+			names := player.list(field)
+			var kept []string
+			for _, name := range *names {
+				if other, ok := byKey[nameKey(name)]; ok && !contains(kept, other.Name) {
+					kept = append(kept, other.Name)
+				}
+			}
+			*names = kept
+		}
+	}
+	return ret, nil
+}
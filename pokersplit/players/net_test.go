@@ -0,0 +1,92 @@
+package players
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestNet(t *testing.T) {
+	cases := []struct {
+		desc    string
+		games   []Players
+		want    Players
+		wantErr bool
+	}{
+		{
+			desc: "balances_netted",
+			games: []Players{
+				{
+					{Name: "alice", BuyIn: Cents(1000), Stack: Cents(2000)},
+					{Name: "bob", BuyIn: Cents(1000), Stack: Cents(0)},
+				},
+				{
+					{Name: "Alice ", BuyIn: Cents(1000), Stack: Cents(400), Methods: []string{MethodCash}},
+					{Name: "BOB", BuyIn: Cents(1000), Stack: Cents(1300)},
+					{Name: "charlie", BuyIn: Cents(1000), Stack: Cents(1300), Avoids: []string{"Böb"}},
+				},
+			},
+			want: Players{
+				{Name: "alice", Stack: Cents(400), Methods: []string{MethodCash}},
+				{Name: "bob", BuyIn: Cents(700)},
+				{Name: "charlie", Stack: Cents(300), Avoids: []string{"bob"}},
+			},
+		},
+		{
+			desc: "paid_debts_ignored",
+			games: []Players{
+				{
+					{Name: "alice", BuyIn: Cents(1000), Stack: Cents(2000)},
+					{Name: "bob", BuyIn: Cents(1000), Stack: Cents(500), Paid: []string{"alice: 5.00"}},
+					{Name: "charlie", BuyIn: Cents(1000), Stack: Cents(500)},
+				},
+			},
+			want: Players{
+				{Name: "alice", Stack: Cents(500)},
+				{Name: "bob"},
+				{Name: "charlie", BuyIn: Cents(500)},
+			},
+		},
+		{
+			desc: "buy_in_and_stack_mismatch",
+			games: []Players{
+				{{Name: "alice", BuyIn: Cents(1000)}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got, err := Net(c.games)
+			if err != nil && !c.wantErr {
+				t.Fatalf("Net() returned an error: %v", err)
+			}
+			if err == nil && c.wantErr {
+				t.Fatalf("Net() didn't return an error, but one was expected")
+			}
+			if c.wantErr {
+				return
+			}
+			if diff := cmp.Diff(c.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Net() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestNetError(t *testing.T) {
+	games := []Players{
+		{{Name: "alice", BuyIn: Cents(1000), Stack: Cents(1000)}},
+		{{Name: "alice", BuyIn: Cents(1000)}},
+	}
+	_, err := Net(games)
+	if err == nil {
+		t.Fatalf("Net() didn't return an error, but one was expected")
+	}
+	// The games are numbered from 1.
+	if want := "game 2"; !strings.Contains(err.Error(), want) {
+		t.Errorf("Net() error = %q, want it to contain %q", err, want)
+	}
+}
@@ -0,0 +1,114 @@
+package players
+
+import (
+	"strings"
+)
+
+// paidDebt formats a debt paid to the creditor, e.g. "bob: 12.50".
+func paidDebt(creditor string, amount Money) string {
+	return creditor + ": " + amount.String()
+}
+
+// parsePaidDebt parses a debt formatted by paidDebt().
+func parsePaidDebt(s string) (creditor string, amount Money, err error) {
+	i := strings.LastIndex(s, ": ")
+	if i < 0 {
+		return "", Money{}, errorf("invalid paid debt %q", s)
+	}
+	amount, err = ParseMoney(s[i+2:])
+	if err != nil {
+		return "", Money{}, errorf("invalid paid debt %q", s)
+	}
+	return s[:i], amount, nil
+}
+
+// parsePaidDebts parses the debts paid by a player, as submitted in a form,
+// and formats them again so that they can be compared. Empty values are
+// ignored, and so are duplicates.
+func parsePaidDebts(values []string) ([]string, error) {
+	var ret []string
+	for _, v := range values {
+		if strings.TrimSpace(v) == "" {
+			continue
+		}
+		creditor, amount, err := parsePaidDebt(v)
+		if err != nil {
+			return nil, err
+		}
+		if debt := paidDebt(NormalizeName(creditor), amount); !contains(ret, debt) {
+			ret = append(ret, debt)
+		}
+	}
+	return ret, nil
+}
+
+// hasPaid returns whether the player marked the debt they owe to the
+// creditor as paid.
+func (p *Player) hasPaid(creditor string, amount Money) bool {
+	return contains(p.Paid, paidDebt(creditor, amount))
+}
+
+// Pay returns a copy of the Players where the debt the debtor owes to the
+// creditor is marked as paid. A debt is identified by its creditor and its
+// amount before rounding, so that it's marked as paid whatever the rounding
+// unit: if the debts change, e.g. because a stack is corrected, the new debts
+// aren't marked as paid.
+func (p Players) Pay(debtor, creditor string) (Players, error) {
+	ret := p.Clone()
+	i, j := ret.find(debtor), ret.find(creditor)
+	if i < 0 {
+		return nil, errorf("player %q not found", debtor)
+	}
+	if j < 0 {
+		return nil, errorf("player %q not found", creditor)
+	}
+	debts, err := ret.CalculateDebts()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range debts[ret[i].Name] {
+		if d.Creditor != ret[j].Name {
+			continue
+		}
+		if debt := paidDebt(d.Creditor, d.Amount); !contains(ret[i].Paid, debt) {
+			ret[i].Paid = append(ret[i].Paid, debt)
+		}
+		return ret, nil
+	}
+	return nil, errorf("%s doesn't owe anything to %s", ret[i].Name, ret[j].Name)
+}
+
+// Unpay reverses Pay(): the debts the debtor marked as paid to the creditor
+// aren't marked as paid anymore, whatever their amount.
+func (p Players) Unpay(debtor, creditor string) (Players, error) {
+	ret := p.Clone()
+	i, j := ret.find(debtor), ret.find(creditor)
+	if i < 0 {
+		return nil, errorf("player %q not found", debtor)
+	}
+	if j < 0 {
+		return nil, errorf("player %q not found", creditor)
+	}
+	var kept []string
+	for _, debt := range ret[i].Paid {
+		if c, _, err := parsePaidDebt(debt); err != nil || c != ret[j].Name {
+			kept = append(kept, debt)
+		}
+	}
+	ret[i].Paid = kept
+	return ret, nil
+}
+
+// Outstanding returns the total of the debts each debtor didn't pay yet. The
+// debtors who paid all their debts are omitted.
+func (d Debts) Outstanding() map[string]Money {
+	ret := make(map[string]Money)
+	for debtor, debts := range d {
+		for _, debt := range debts {
+			if !debt.Paid {
+				ret[debtor] = ret[debtor].Add(debt.Amount)
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,90 @@
+package players
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestParsePaidDebt(t *testing.T) {
+	cases := []struct {
+		s            string
+		wantCreditor string
+		wantAmount   Money
+		wantErr      bool
+	}{
+		{s: "bob: 12.50", wantCreditor: "bob", wantAmount: Cents(1250)},
+		{s: "Mr: Bob: 0.125", wantCreditor: "Mr: Bob", wantAmount: mustParseMoney("0.125")},
+		{s: "bob", wantErr: true},
+		{s: "bob: abc", wantErr: true},
+	}
+	for _, c := range cases {
+		creditor, amount, err := parsePaidDebt(c.s)
+		if err != nil && !c.wantErr {
+			t.Errorf("parsePaidDebt(%q) returned an error: %v", c.s, err)
+			continue
+		}
+		if err == nil && c.wantErr {
+			t.Errorf("parsePaidDebt(%q) didn't return an error, but one was expected", c.s)
+			continue
+		}
+		if creditor != c.wantCreditor || !amount.Equal(c.wantAmount) {
+			t.Errorf("parsePaidDebt(%q) = %q, %v, want %q, %v", c.s, creditor, amount, c.wantCreditor, c.wantAmount)
+		}
+	}
+}
+
+func TestPay(t *testing.T) {
+	p := Players{{Name: "alice", BuyIn: Cents(1000), Stack: Cents(1500)}, {Name: "bob", BuyIn: Cents(1000), Stack: Cents(500)}, {Name: "charlie"}}
+	paid, err := p.Pay("Bob", "ALICE")
+	if err != nil {
+		t.Fatalf("Players.Pay() returned an error: %v", err)
+	}
+	want := p.Clone()
+	want[1].Paid = []string{"alice: 5.00"}
+	if diff := cmp.Diff(want, paid, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Players.Pay() mismatch (-want +got):\n%s", diff)
+	}
+	if len(p[1].Paid) != 0 {
+		t.Errorf("Players.Pay() modified the original players")
+	}
+
+	// The debts marked as paid with an outdated amount are unmarked too.
+	paid[1].Paid = append(paid[1].Paid, "alice: 4.00")
+	unpaid, err := paid.Unpay("BOB", "Alice")
+	if err != nil {
+		t.Fatalf("Players.Unpay() returned an error: %v", err)
+	}
+	if diff := cmp.Diff(p, unpaid, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Players.Unpay() mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, c := range []struct {
+		debtor, creditor string
+	}{
+		{debtor: "dave", creditor: "alice"},
+		{debtor: "bob", creditor: "dave"},
+		// Charlie doesn't owe anything.
+		{debtor: "charlie", creditor: "alice"},
+	} {
+		if _, err := p.Pay(c.debtor, c.creditor); err == nil {
+			t.Errorf("Players.Pay(%q, %q) didn't return an error, but one was expected", c.debtor, c.creditor)
+		}
+	}
+}
+
+func TestOutstanding(t *testing.T) {
+	d := Debts{
+		"bob": {
+			{Creditor: "alice", Amount: Cents(500), Paid: true},
+			{Creditor: "charlie", Amount: Cents(250)},
+			{Creditor: "dave", Amount: Cents(100)},
+		},
+		"eve": {{Creditor: "alice", Amount: Cents(300), Paid: true}},
+	}
+	want := map[string]Money{"bob": Cents(350)}
+	if diff := cmp.Diff(want, d.Outstanding()); diff != "" {
+		t.Errorf("Debts.Outstanding() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,174 @@
+package players
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Formats of the payment requests.
+const (
+	// FormatQRBill is the format of the Swiss QR-bill, for payments in CHF or
+	// EUR.
+	FormatQRBill = "qrbill"
+	// FormatEPC is the format of the EPC QR code of a SEPA credit transfer,
+	// also known as GiroCode, for payments in EUR.
+	FormatEPC = "epc"
+)
+
+// maxPayment is the largest amount supported by the payment requests.
+var maxPayment = Cents(99999999999)
+
+// PaymentRequest is what's needed to generate a QR code which a debtor can
+// scan with their banking app to pay a debt.
+type PaymentRequest struct {
+	// Format is either FormatQRBill or FormatEPC.
+	Format string
+	// Payload is the content of the QR code.
+	Payload string
+}
+
+// NewPaymentRequest returns the payment request of the debt the debtor owes
+// to the creditor, in the currency of the game. It is a Swiss QR-bill if the
+// creditor's IBAN is from Switzerland or Liechtenstein, or an EPC QR code
+// otherwise. The amount is never converted: an error is returned if the
+// format doesn't support the currency, e.g. EPC QR codes are in EUR only. If
+// the currency isn't set, it is assumed to be CHF for a Swiss QR-bill and EUR
+// for an EPC QR code.
+func NewPaymentRequest(debtor string, creditor *Player, amount Money, currency string) (*PaymentRequest, error) {
+	if creditor.IBAN == "" {
+		return nil, errorf("%q didn't provide their IBAN", creditor.Name)
+	}
+	if err := validateIBAN(creditor.IBAN); err != nil {
+		return nil, errorf("the IBAN of %q %v", creditor.Name, err)
+	}
+	if amount.Sign() <= 0 || amount.Cmp(maxPayment) > 0 {
+		return nil, errorf("%s can't be paid using a payment request", amount)
+	}
+	if !amount.Equal(amount.Floor(Cents(1))) {
+		return nil, errorf("%s can't be paid using a payment request", amount)
+	}
+	message := truncate("PokerSplit: "+debtor+" - "+creditor.Name, 140)
+	country := creditor.IBAN[:2]
+	if country != "CH" && country != "LI" {
+		if currency != "" && currency != "EUR" {
+			return nil, errorf("the IBAN of %q only accepts payment requests in EUR, not %s", creditor.Name, currency)
+		}
+		return &PaymentRequest{
+			Format: FormatEPC,
+			Payload: strings.Join([]string{
+				"BCD",
+				"002",
+				"1", // UTF-8
+				"SCT",
+				"", // The BIC is optional within the EEA.
+				truncate(creditor.Name, 70),
+				creditor.IBAN,
+				"EUR" + amount.String(),
+				"", // Purpose.
+				"", // Structured reference.
+				message,
+			}, "\n"),
+		}, nil
+	}
+
+	switch currency {
+	case "":
+		currency = "CHF"
+	case "CHF", "EUR":
+	default:
+		return nil, errorf("the IBAN of %q only accepts payment requests in CHF or EUR, not %s", creditor.Name, currency)
+	}
+	if isQRIBAN(creditor.IBAN) {
+		return nil, errorf("the IBAN of %q is a QR-IBAN, which requires a reference", creditor.Name)
+	}
+	postalCode, town := splitAddress(creditor.Address)
+	if postalCode == "" || town == "" {
+		return nil, errorf("the postal code and town of %q are needed for a Swiss QR-bill", creditor.Name)
+	}
+	lines := []string{
+		"SPC",
+		"0200",
+		"1", // UTF-8
+		creditor.IBAN,
+		// Creditor, with a structured address.
+		"S",
+		truncate(creditor.Name, 70),
+		"", // Street.
+		"", // Building number.
+		truncate(postalCode, 16),
+		truncate(town, 35),
+		country,
+		// Ultimate creditor, reserved for future use.
+		"", "", "", "", "", "", "",
+		amount.String(),
+		currency,
+		// Debtor, who is unknown.
+		"", "", "", "", "", "", "",
+		"NON",
+		"", // Reference.
+		message,
+		"EPD",
+	}
+	return &PaymentRequest{Format: FormatQRBill, Payload: strings.Join(lines, "\n")}, nil
+}
+
+// truncate returns s truncated to n characters.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// splitAddress splits an address made of a postal code and a town, e.g.
+// "8000 Zürich".
+func splitAddress(address string) (postalCode, town string) {
+	parts := strings.SplitN(strings.TrimSpace(address), " ", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], strings.TrimSpace(parts[1])
+}
+
+// isQRIBAN returns whether the Swiss IBAN is a QR-IBAN: its institution ID is
+// between 30000 and 31999.
+func isQRIBAN(iban string) bool {
+	return len(iban) >= 9 && (iban[4:6] == "30" || iban[4:6] == "31")
+}
+
+// NormalizeIBAN returns the IBAN without spaces, in upper case.
+func NormalizeIBAN(iban string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(iban), ""))
+}
+
+// validateIBAN checks the structure and the check digits of a normalized
+// IBAN.
+func validateIBAN(iban string) error {
+	if len(iban) < 15 || len(iban) > 34 {
+		return errorf("is not a valid IBAN")
+	}
+	for i, r := range iban {
+		letter, digit := r >= 'A' && r <= 'Z', r >= '0' && r <= '9'
+		if (i < 2 && !letter) || (i >= 2 && i < 4 && !digit) || (!letter && !digit) {
+			return errorf("is not a valid IBAN")
+		}
+	}
+	// Move the country code and the check digits to the end, replace the
+	// letters by numbers, A being 10, and compute the remainder of the
+	// division by 97, which must be 1.
+	var digits strings.Builder
+	for _, r := range iban[4:] + iban[:4] {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(r - 'A' + 10)))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+	n, _ := new(big.Int).SetString(digits.String(), 10)
+	if new(big.Int).Mod(n, big.NewInt(97)).Int64() != 1 {
+		return errorf("is not a valid IBAN")
+	}
+	return nil
+}
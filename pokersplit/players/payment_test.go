@@ -0,0 +1,151 @@
+package players
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewPaymentRequest(t *testing.T) {
+	cases := []struct {
+		desc       string
+		creditor   *Player
+		amount     Money
+		currency   string
+		wantFormat string
+		wantLines  []string
+		wantErr    bool
+	}{
+		{
+			desc:       "epc",
+			creditor:   &Player{Name: "alice", IBAN: "DE89370400440532013000"},
+			amount:     Cents(1250),
+			wantFormat: FormatEPC,
+			wantLines: []string{
+				"BCD", "002", "1", "SCT", "", "alice", "DE89370400440532013000", "EUR12.50", "", "",
+				"PokerSplit: bob - alice",
+			},
+		},
+		{
+			desc:       "qr_bill",
+			creditor:   &Player{Name: "alice", IBAN: "CH9300762011623852957", Address: "8000 Zürich"},
+			amount:     Cents(1250),
+			wantFormat: FormatQRBill,
+			wantLines: []string{
+				"SPC", "0200", "1", "CH9300762011623852957",
+				"S", "alice", "", "", "8000", "Zürich", "CH",
+				"", "", "", "", "", "", "",
+				"12.50", "CHF",
+				"", "", "", "", "", "", "",
+				"NON", "", "PokerSplit: bob - alice", "EPD",
+			},
+		},
+		{
+			desc:       "qr_bill_in_eur",
+			creditor:   &Player{Name: "alice", IBAN: "CH9300762011623852957", Address: "8000 Zürich"},
+			amount:     Cents(1250),
+			currency:   "EUR",
+			wantFormat: FormatQRBill,
+			wantLines: []string{
+				"SPC", "0200", "1", "CH9300762011623852957",
+				"S", "alice", "", "", "8000", "Zürich", "CH",
+				"", "", "", "", "", "", "",
+				"12.50", "EUR",
+				"", "", "", "", "", "", "",
+				"NON", "", "PokerSplit: bob - alice", "EPD",
+			},
+		},
+		{
+			desc:     "epc_in_chf",
+			creditor: &Player{Name: "alice", IBAN: "DE89370400440532013000"},
+			amount:   Cents(1250),
+			currency: "CHF",
+			wantErr:  true,
+		},
+		{
+			desc:     "qr_bill_in_usd",
+			creditor: &Player{Name: "alice", IBAN: "CH9300762011623852957", Address: "8000 Zürich"},
+			amount:   Cents(1250),
+			currency: "USD",
+			wantErr:  true,
+		},
+		{
+			desc:     "no_iban",
+			creditor: &Player{Name: "alice"},
+			amount:   Cents(1250),
+			wantErr:  true,
+		},
+		{
+			desc:     "invalid_iban",
+			creditor: &Player{Name: "alice", IBAN: "DE00"},
+			amount:   Cents(1250),
+			wantErr:  true,
+		},
+		{
+			desc:     "qr_bill_without_address",
+			creditor: &Player{Name: "alice", IBAN: "CH9300762011623852957"},
+			amount:   Cents(1250),
+			wantErr:  true,
+		},
+		{
+			desc:     "qr_iban",
+			creditor: &Player{Name: "alice", IBAN: "CH4431999123000889012", Address: "8000 Zürich"},
+			amount:   Cents(1250),
+			wantErr:  true,
+		},
+		{
+			desc:     "fractions_of_a_cent",
+			creditor: &Player{Name: "alice", IBAN: "DE89370400440532013000"},
+			amount:   mustParseMoney("0.125"),
+			wantErr:  true,
+		},
+		{
+			desc:     "zero",
+			creditor: &Player{Name: "alice", IBAN: "DE89370400440532013000"},
+			wantErr:  true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got, err := NewPaymentRequest("bob", c.creditor, c.amount, c.currency)
+			if err != nil && !c.wantErr {
+				t.Fatalf("NewPaymentRequest() returned an error: %v", err)
+			}
+			if err == nil && c.wantErr {
+				t.Fatalf("NewPaymentRequest() didn't return an error, but one was expected")
+			}
+			if c.wantErr {
+				return
+			}
+			if got.Format != c.wantFormat {
+				t.Errorf("NewPaymentRequest().Format = %q, want %q", got.Format, c.wantFormat)
+			}
+			if want := strings.Join(c.wantLines, "\n"); got.Payload != want {
+				t.Errorf("NewPaymentRequest().Payload = %q, want %q", got.Payload, want)
+			}
+		})
+	}
+}
+
+func TestValidateIBAN(t *testing.T) {
+	cases := []struct {
+		iban    string
+		wantErr bool
+	}{
+		{iban: "DE89370400440532013000"},
+		{iban: "CH9300762011623852957"},
+		{iban: "GB29NWBK60161331926819"},
+		{iban: "CH9300762011623852958", wantErr: true},
+		{iban: "DE8937040044", wantErr: true},
+		{iban: "1289370400440532013000", wantErr: true},
+		{iban: "DE89-370400440532013000", wantErr: true},
+	}
+	for _, c := range cases {
+		err := validateIBAN(c.iban)
+		if err != nil && !c.wantErr {
+			t.Errorf("validateIBAN(%q) returned an error: %v", c.iban, err)
+		}
+		if err == nil && c.wantErr {
+			t.Errorf("validateIBAN(%q) didn't return an error, but one was expected", c.iban)
+		}
+	}
+}
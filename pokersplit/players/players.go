@@ -6,21 +6,113 @@ import (
 	"compress/gzip"
 	"encoding/base64"
 	"encoding/json"
-	"fmt"
-	"math"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+
+	"golang.org/x/text/language"
 )
 
 // Player holds the player's data.
 type Player struct {
 	// Name of the player.
 	Name string `json:"p"`
-	// BuyIn is how much cash money the player invested, in cents.
-	BuyIn int `json:"b,omitempty"`
-	// Stack is how much money the player has, in cents.
-	Stack int `json:"s,omitempty"`
+	// BuyIn is how much cash money the player invested.
+	BuyIn Money `json:"b"`
+	// Stack is how much money the player has.
+	Stack Money `json:"s"`
+	// Methods are the payment methods the player can use to pay and get paid.
+	// The player accepts all of them if empty.
+	Methods []string `json:"m,omitempty"`
+	// Avoids are the names of the players this player must not settle debts
+	// with, e.g. their partner.
+	Avoids []string `json:"x,omitempty"`
+	// Prefers are the names of the players this player prefers to settle
+	// debts with.
+	Prefers []string `json:"f,omitempty"`
+	// IBAN is the normalized IBAN of the player's bank account, used to
+	// generate the payment requests of the debts owed to them.
+	IBAN string `json:"i,omitempty"`
+	// Address is the postal code and town of the player, e.g. "8000 Zürich",
+	// needed for Swiss QR-bills.
+	Address string `json:"a,omitempty"`
+	// Paid are the debts the player already paid, formatted by paidDebt().
+	Paid []string `json:"d,omitempty"`
+}
+
+// Net returns how much the player won, which is negative if they lost.
+func (p *Player) Net() Money {
+	return p.Stack.Sub(p.BuyIn)
+}
+
+// Payment methods the players can accept.
+const (
+	MethodCash   = "cash"
+	MethodMobile = "mobile"
+)
+
+// Methods lists all the supported payment methods.
+var Methods = []string{MethodCash, MethodMobile}
+
+// avoids returns whether the players must not settle debts with each other.
+func (p *Player) avoids(o *Player) bool {
+	return contains(p.Avoids, o.Name) || contains(o.Avoids, p.Name)
+}
+
+// prefers returns whether either player prefers to settle debts with the
+// other.
+func (p *Player) prefers(o *Player) bool {
+	return contains(p.Prefers, o.Name) || contains(o.Prefers, p.Name)
+}
+
+// list returns the field of the player holding a list of values: the names of
+// the players they avoid or prefer, or the debts they paid, depending on the
+// field: FieldAvoids, FieldPrefers or FieldPaid.
+func (p *Player) list(field string) *[]string {
+	switch field {
+	case FieldAvoids:
+		return &p.Avoids
+	case FieldPaid:
+		return &p.Paid
+	}
+	return &p.Prefers
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// remove returns the list without s.
+func remove(list []string, s string) []string {
+	var ret []string
+	for _, item := range list {
+		if item != s {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
+
+// shares returns whether the players have a payment method in common. A
+// player who didn't declare any payment method accepts all of them.
+func (p *Player) shares(o *Player) bool {
+	if len(p.Methods) == 0 || len(o.Methods) == 0 {
+		return true
+	}
+	for _, m := range p.Methods {
+		for _, n := range o.Methods {
+			if m == n {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 // Players is a collection of Player.
@@ -31,7 +123,24 @@ func (p Players) ToBase64() (string, error) {
 	if len(p) == 0 {
 		return "", nil
 	}
+	return encode(p)
+}
+
+// FromBase64 decodes Players which were base64 URL-encoded using ToBase64().
+func FromBase64(data string) (Players, error) {
+	var ret Players
+	if data == "" {
+		return ret, nil
+	}
+	if err := decode(data, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
 
+// encode serializes v to JSON, compresses it using gzip and encodes it in
+// base64 URL-encoding.
+func encode(v interface{}) (string, error) {
 	var buf bytes.Buffer
 	encode := func() error {
 		base64Encoder := base64.NewEncoder(base64.URLEncoding, &buf)
@@ -39,7 +148,7 @@ func (p Players) ToBase64() (string, error) {
 		gzipEncoder := gzip.NewWriter(base64Encoder)
 		defer gzipEncoder.Close()
 		jsonEncoder := json.NewEncoder(gzipEncoder)
-		if err := jsonEncoder.Encode(p); err != nil {
+		if err := jsonEncoder.Encode(v); err != nil {
 			return err
 		}
 		return nil
@@ -51,82 +160,279 @@ func (p Players) ToBase64() (string, error) {
 	return buf.String(), nil
 }
 
-// FromBase64 decodes Players which were base64 URL-encoded using ToBase64().
-func FromBase64(data string) (Players, error) {
-	var ret Players
-	if data == "" {
-		return ret, nil
-	}
-
+// decode reverses encode(), storing the result in the value pointed to by v.
+func decode(data string, v interface{}) error {
 	base64Decoder := base64.NewDecoder(base64.URLEncoding, strings.NewReader(data))
 	gzipDecoder, err := gzip.NewReader(base64Decoder)
 	if err != nil {
-		return nil, fmt.Errorf("gzip decompression failed: %v", err)
+		return errorf("gzip decompression failed: %v", err)
 	}
 	jsonDecoder := json.NewDecoder(gzipDecoder)
-	if err := jsonDecoder.Decode(&ret); err != nil {
-		return nil, fmt.Errorf("failed to decode JSON: %v", err)
+	if err := jsonDecoder.Decode(v); err != nil {
+		return errorf("failed to decode JSON: %v", err)
 	}
-	return ret, nil
+	return nil
 }
 
 // FromForm creates Players from an HTML form's data. It expects the form to
 // contain tuples in the form of fieldNameX, where fieldName is the name of
-// the field: "player", "buyin" and "stack", and X is an ID, the same for all
-// fields part of the same tuple.
-func FromForm(form url.Values) (Players, error) {
+// the field: "player", "buyin", "stack", "methods", "avoids", "prefers",
+// "iban", "address" and "paid", and X is an ID, the same for all fields part
+// of the same tuple. Missing amounts are considered to be zero. The "methods"
+// field may have multiple values, one per payment method accepted by the
+// player, and so may "avoids" and "prefers", one per name of player, and
+// "paid", one per paid debt. The amounts are parsed according to the
+// conventions of the language. If any field is invalid, the returned error is
+// a FieldErrors. The tuples are read in the order of their ID, so that a
+// duplicate name is reported on the later tuple.
+func FromForm(form url.Values, tag language.Tag) (Players, error) {
 	var ret Players
-	// Keep track of players' names to detect duplicates.
-	playerNames := make(map[string]bool)
-	for k, v := range form {
-		// Only consider the player fields. The other ones are infered using its ID.
-		if !strings.HasPrefix(k, "player") {
-			continue
-		}
+	decimalSep := DecimalSeparator(tag)
+	errs := make(FieldErrors)
+	playerNames := make(names)
+	// Only consider the player fields. The other ones are infered using its ID.
+	for _, k := range playerKeys(form) {
+		v := form[k]
 		if len(v) != 1 || strings.TrimSpace(v[0]) == "" {
 			continue
 		}
-		name := v[0]
-		if playerNames[name] {
-			return nil, fmt.Errorf("duplicate player with name %q", name)
+		name := NormalizeName(v[0])
+		if err := playerNames.add(name); err != nil {
+			errs[k] = err
 		}
-		playerNames[name] = true
 
 		i := strings.TrimPrefix(k, "player")
-		cash, err := strconv.ParseFloat(form.Get("buyin"+i), 64)
+		buyIn, err := parseAmount(form.Get("buyin"+i), decimalSep)
+		if err != nil {
+			errs["buyin"+i] = err
+		}
+		stack, err := parseAmount(form.Get("stack"+i), decimalSep)
+		if err != nil {
+			errs["stack"+i] = err
+		}
+		methods, err := parseMethods(form["methods"+i])
 		if err != nil {
-			cash = 0
+			errs["methods"+i] = err
 		}
-		stack, err := strconv.ParseFloat(form.Get("stack"+i), 64)
+		paid, err := parsePaidDebts(form["paid"+i])
 		if err != nil {
-			stack = 0
+			errs["paid"+i] = err
+		}
+		iban := NormalizeIBAN(form.Get("iban" + i))
+		if iban != "" {
+			if err := validateIBAN(iban); err != nil {
+				errs["iban"+i] = err
+			}
 		}
 
-		// The Player struct expects the amounts to be in cents. The ones in the
-		// form aren't. Multiply them by 100 to convert them to cents.
 		ret = append(ret, &Player{
-			Name:  name,
-			BuyIn: int(math.Round(cash * 100)),
-			Stack: int(math.Round(stack * 100)),
+			Name:    name,
+			BuyIn:   buyIn,
+			Stack:   stack,
+			Methods: methods,
+			Avoids:  normalizeNames(form["avoids"+i]),
+			Prefers: normalizeNames(form["prefers"+i]),
+			IBAN:    iban,
+			Address: NormalizeName(form.Get("address" + i)),
+			Paid:    paid,
 		})
 	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
 	return ret, nil
 }
 
-// BuyIn returns the total amount of money invested by all Players, in cents.
-func (p Players) BuyIn() int {
-	ret := 0
+// playerKeys returns the names of the "player" fields of the form, sorted by
+// their ID: numerically, then alphabetically for the IDs which aren't numbers.
+func playerKeys(form url.Values) []string {
+	var ret []string
+	for k := range form {
+		if strings.HasPrefix(k, "player") {
+			ret = append(ret, k)
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		a, errA := strconv.Atoi(strings.TrimPrefix(ret[i], "player"))
+		b, errB := strconv.Atoi(strings.TrimPrefix(ret[j], "player"))
+		switch {
+		case errA == nil && errB == nil:
+			return a < b
+		case errA == nil || errB == nil:
+			return errA == nil
+		}
+		return ret[i] < ret[j]
+	})
+	return ret
+}
+
+// Add returns a copy of the Players along with new players having the given
+// names, who didn't buy in yet. Empty names are ignored. It returns an error
+// if a name is already used, or used more than once.
+func (p Players) Add(newNames ...string) (Players, error) {
+	ret := p.Clone()
+	playerNames := make(names)
+	for _, player := range ret {
+		playerNames.add(player.Name)
+	}
+	for _, name := range newNames {
+		if name = NormalizeName(name); name == "" {
+			continue
+		}
+		if err := playerNames.add(name); err != nil {
+			return nil, err
+		}
+		ret = append(ret, &Player{Name: name})
+	}
+	return ret, nil
+}
+
+// Rename returns a copy of the Players where the player named oldName is
+// renamed to newName, along with the corresponding Change. It returns an error
+// if newName is already used by another player.
+func (p Players) Rename(oldName, newName string) (Players, Change, error) {
+	newName = NormalizeName(newName)
+	if newName == "" {
+		return nil, Change{}, errorf("the new name of %q is empty", oldName)
+	}
+	ret := p.Clone()
+	i := ret.find(oldName)
+	if i < 0 {
+		return nil, Change{}, errorf("player %q not found", oldName)
+	}
+	playerNames := make(names)
+	for j, player := range ret {
+		if j != i {
+			playerNames.add(player.Name)
+		}
+	}
+	if err := playerNames.add(newName); err != nil {
+		return nil, Change{}, err
+	}
+	oldName = ret[i].Name
+	ret.rename(oldName, newName)
+	return ret, Change{Player: oldName, Field: FieldName, Old: oldName, New: newName}, nil
+}
+
+// rename renames the player, including in the constraints and the paid debts
+// of the other players.
+func (p Players) rename(oldName, newName string) {
 	for _, player := range p {
-		ret += player.BuyIn
+		if player.Name == oldName {
+			player.Name = newName
+		}
+		for _, field := range []string{FieldAvoids, FieldPrefers} {
+			names := player.list(field)
+			for j, name := range *names {
+				if name == oldName {
+					(*names)[j] = newName
+				}
+			}
+		}
+		for j, debt := range player.Paid {
+			if creditor, amount, err := parsePaidDebt(debt); err == nil && creditor == oldName {
+				player.Paid[j] = paidDebt(newName, amount)
+			}
+		}
+	}
+}
+
+// Rebuy returns a copy of the Players with the buy-in of the player having
+// the given name, or a variant of it, increased by the amount.
+func (p Players) Rebuy(name string, amount Money) (Players, error) {
+	if amount.Sign() <= 0 {
+		return nil, errorf("the amount of a rebuy must be positive")
+	}
+	ret := p.Clone()
+	i := ret.find(name)
+	if i < 0 {
+		return nil, errorf("player %q not found", name)
+	}
+	ret[i].BuyIn = ret[i].BuyIn.Add(amount)
+	return ret, nil
+}
+
+// Remove returns a copy of the Players without the player with the given name.
+func (p Players) Remove(name string) (Players, error) {
+	ret := p.Clone()
+	i := ret.find(name)
+	if i < 0 {
+		return nil, errorf("player %q not found", name)
+	}
+	name = ret[i].Name
+	ret = append(ret[:i], ret[i+1:]...)
+	for _, player := range ret {
+		player.Avoids = remove(player.Avoids, name)
+		player.Prefers = remove(player.Prefers, name)
+		var paid []string
+		for _, debt := range player.Paid {
+			if creditor, _, err := parsePaidDebt(debt); err != nil || creditor != name {
+				paid = append(paid, debt)
+			}
+		}
+		player.Paid = paid
+	}
+	return ret, nil
+}
+
+// Constrain returns a copy of the Players where the player named name avoids,
+// or prefers, settling debts with the player named other, depending on the
+// field: FieldAvoids or FieldPrefers.
+func (p Players) Constrain(name, field, other string) (Players, error) {
+	if field != FieldAvoids && field != FieldPrefers {
+		return nil, errorf("unknown field %q", field)
+	}
+	ret := p.Clone()
+	i, j := ret.find(name), ret.find(other)
+	if i < 0 {
+		return nil, errorf("player %q not found", name)
+	}
+	if j < 0 {
+		return nil, errorf("player %q not found", other)
+	}
+	if i == j {
+		return nil, errorf("%q can't settle debts with themselves", ret[i].Name)
+	}
+	player := ret[i]
+	other = ret[j].Name
+	if field == FieldAvoids && player.prefers(ret[j]) || field == FieldPrefers && player.avoids(ret[j]) {
+		return nil, errorf("%q can't both avoid and prefer settling debts with %q", player.Name, other)
+	}
+	if names := player.list(field); !contains(*names, other) {
+		*names = append(*names, other)
+	}
+	return ret, nil
+}
+
+// Unconstrain reverses Constrain().
+func (p Players) Unconstrain(name, field, other string) (Players, error) {
+	if field != FieldAvoids && field != FieldPrefers {
+		return nil, errorf("unknown field %q", field)
+	}
+	ret := p.Clone()
+	i := ret.find(name)
+	if i < 0 {
+		return nil, errorf("player %q not found", name)
+	}
+	names := ret[i].list(field)
+	*names = remove(*names, other)
+	return ret, nil
+}
+
+// BuyIn returns the total amount of money invested by all Players.
+func (p Players) BuyIn() Money {
+	var ret Money
+	for _, player := range p {
+		ret = ret.Add(player.BuyIn)
 	}
 	return ret
 }
 
-// Stack returns the sum of all Players' stacks, in cents.
-func (p Players) Stack() int {
-	ret := 0
+// Stack returns the sum of all Players' stacks.
+func (p Players) Stack() Money {
+	var ret Money
 	for _, player := range p {
-		ret += player.Stack
+		ret = ret.Add(player.Stack)
 	}
 	return ret
 }
@@ -135,39 +441,43 @@ func (p Players) Stack() int {
 type Debt struct {
 	// Creditor is the name of the person to whom money is owed.
 	Creditor string
-	// Amount owed, in cents.
-	Amount int
+	// Amount owed.
+	Amount Money
+	// Unmatched is true if the debtor and the creditor don't have any payment
+	// method in common.
+	Unmatched bool
+	// Paid is true if the debtor marked the debt as paid.
+	Paid bool
 }
 
-// Debts is a collection of debts, grouped by debtor.
+// Debts is a collection of debts, grouped by debtor. The debts of a debtor
+// are sorted from the largest to the smallest, then by creditor.
 type Debts map[string][]Debt
 
-// CalculateDebts figures out who owes how much to whom. To limit the number of
-// transactions, the best looser (the player who lost the least) owes monney to
-// the best winner (the player who won the most). After each iteration, the
-// balances are updated and the best winner/looser are re-identified.
+// Debtors returns the names of the debtors, sorted according to the language.
+func (d Debts) Debtors(tag language.Tag) []string {
+	var ret []string
+	for debtor := range d {
+		ret = append(ret, debtor)
+	}
+	cl := NewCollator(tag)
+	sort.Slice(ret, func(i, j int) bool {
+		if c := cl.CompareString(ret[i], ret[j]); c != 0 {
+			return c < 0
+		}
+		return lessName(ret[i], ret[j])
+	})
+	return ret
+}
+
+// CalculateDebts figures out who owes how much to whom, without rounding the
+// amounts. See Settle().
 func (p Players) CalculateDebts() (Debts, error) {
-	if p.BuyIn() != p.Stack() {
-		return nil, fmt.Errorf("the total of the buy-ins doesn't match the total of the stacks")
-	}
-	ret := make(Debts)
-	winners, loosers := p.winnersAndLoosers()
-	// The algorithm modifies the stacks to keep track of the debts already
-	// taken into account. Once all winners have their stack equal to their
-	// buy-in, it means that all debts are settled.
-	for winners.BuyIn() != winners.Stack() {
-		bLooser := loosers.best()
-		bWinner := winners.best()
-		amount := bLooser.BuyIn - bLooser.Stack
-		if bWinner.Stack-bWinner.BuyIn < amount {
-			amount = bWinner.Stack - bWinner.BuyIn
-		}
-		bLooser.Stack += amount
-		bWinner.Stack -= amount
-		debt := Debt{Creditor: bWinner.Name, Amount: amount}
-		ret[bLooser.Name] = append(ret[bLooser.Name], debt)
+	s, err := p.Settle(SettleOptions{})
+	if err != nil {
+		return nil, err
 	}
-	return ret, nil
+	return s.Debts, nil
 }
 
 // winnersAndLoosers returns the players which won/lost money.
@@ -175,13 +485,9 @@ func (p Players) winnersAndLoosers() (winners, loosers Players) {
 	for _, aPlayer := range p {
 		// Make a copy of the player, because the algorithm modifies its stack
 		// to settle the debts.
-		player := &Player{
-			Name:  aPlayer.Name,
-			BuyIn: aPlayer.BuyIn,
-			Stack: aPlayer.Stack,
-		}
-		gain := int(player.Stack - player.BuyIn)
-		if gain >= 0 {
+		clone := *aPlayer
+		player := &clone
+		if player.Stack.Cmp(player.BuyIn) >= 0 {
 			winners = append(winners, player)
 		} else {
 			loosers = append(loosers, player)
@@ -191,14 +497,14 @@ func (p Players) winnersAndLoosers() (winners, loosers Players) {
 }
 
 // best returns the Player who won the most, or lost the least, if they all lost.
-// Players having a balance of zero are ignored.
+// Players having a balance of zero are ignored. Ties are broken by name.
 func (p Players) best() *Player {
 	best := -1
 	for i := range p {
 		// Ignore the players having a balance of zero, their debt is considered
 		// settled. They must be ignored because otherwise they are returned
 		// instead of the player who lost the least in case they all lost.
-		if p[i].BuyIn == p[i].Stack {
+		if p[i].BuyIn.Equal(p[i].Stack) {
 			continue
 		}
 		// This is the first iteration of a player having a non-zero balance.
@@ -207,7 +513,8 @@ func (p Players) best() *Player {
 			best = i
 			continue
 		}
-		if p[i].Stack-p[i].BuyIn > p[best].Stack-p[best].BuyIn {
+		c := p[i].Stack.Sub(p[i].BuyIn).Cmp(p[best].Stack.Sub(p[best].BuyIn))
+		if c > 0 || c == 0 && lessName(p[i].Name, p[best].Name) {
 			best = i
 		}
 	}
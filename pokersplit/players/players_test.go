@@ -7,10 +7,10 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/text/language"
 )
 
 var sortPlayer = cmpopts.SortSlices(func(a, b *Player) bool { return a.Name < b.Name })
-var sortDebt = cmpopts.SortSlices(func(a, b Debt) bool { return a.Creditor < b.Creditor })
 
 // TestBase64 tests the base64 encoding and decoding functions.
 func TestBase64(t *testing.T) {
@@ -27,7 +27,7 @@ func TestBase64(t *testing.T) {
 		},
 		{
 			name:    "one_player_all_fields",
-			players: Players{{Name: "Alice", BuyIn: 100, Stack: 8575}},
+			players: Players{{Name: "Alice", BuyIn: Cents(100), Stack: Cents(8575)}},
 		},
 		{
 			name:    "two_players_names_only",
@@ -75,7 +75,7 @@ func TestFromForm(t *testing.T) {
 				"buyin0":  []string{"50.25"},
 				"stack0":  []string{"123.40"},
 			},
-			want: Players{{Name: "alice", BuyIn: 5025, Stack: 12340}},
+			want: Players{{Name: "alice", BuyIn: Cents(5025), Stack: Cents(12340)}},
 		},
 		{
 			desc: "one_player_and_other_irrelevant_fields",
@@ -88,7 +88,7 @@ func TestFromForm(t *testing.T) {
 				"buyin2":  []string{"3000"},
 				"stack3":  []string{"4000"},
 			},
-			want: Players{{Name: "alice", BuyIn: 5025, Stack: 12340}},
+			want: Players{{Name: "alice", BuyIn: Cents(5025), Stack: Cents(12340)}},
 		},
 		{
 			desc: "one_player_non_zero_index",
@@ -97,7 +97,7 @@ func TestFromForm(t *testing.T) {
 				"buyin2":  []string{"50.25"},
 				"stack2":  []string{"123.40"},
 			},
-			want: Players{{Name: "alice", BuyIn: 5025, Stack: 12340}},
+			want: Players{{Name: "alice", BuyIn: Cents(5025), Stack: Cents(12340)}},
 		},
 		{
 			desc: "two_players",
@@ -110,8 +110,8 @@ func TestFromForm(t *testing.T) {
 				"stack1":  []string{"15"},
 			},
 			want: Players{
-				{Name: "alice", BuyIn: 5025, Stack: 12340},
-				{Name: "bob", BuyIn: 2275, Stack: 1500},
+				{Name: "alice", BuyIn: Cents(5025), Stack: Cents(12340)},
+				{Name: "bob", BuyIn: Cents(2275), Stack: Cents(1500)},
 			},
 		},
 		{
@@ -138,7 +138,7 @@ func TestFromForm(t *testing.T) {
 				"player0": []string{"alice"},
 				"stack0":  []string{"123.40"},
 			},
-			want: Players{{Name: "alice", Stack: 12340}},
+			want: Players{{Name: "alice", Stack: Cents(12340)}},
 		},
 		{
 			desc: "missing_stack",
@@ -146,7 +146,7 @@ func TestFromForm(t *testing.T) {
 				"player0": []string{"alice"},
 				"buyin0":  []string{"50.25"},
 			},
-			want: Players{{Name: "alice", BuyIn: 5025}},
+			want: Players{{Name: "alice", BuyIn: Cents(5025)}},
 		},
 		{
 			desc: "duplicate_name",
@@ -160,10 +160,107 @@ func TestFromForm(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			desc: "duplicate_name_variants",
+			form: url.Values{
+				"player0": []string{"Alice"},
+				"player1": []string{"alice "},
+				"player2": []string{"Alíce"},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "name_normalized",
+			form: url.Values{
+				"player0": []string{"  Mary   Ann "},
+			},
+			want: Players{{Name: "Mary Ann"}},
+		},
+		{
+			desc: "payment_methods",
+			form: url.Values{
+				"player0":  []string{"alice"},
+				"methods0": []string{MethodMobile, MethodCash, MethodMobile},
+				"player1":  []string{"bob"},
+			},
+			want: Players{
+				{Name: "alice", Methods: []string{MethodCash, MethodMobile}},
+				{Name: "bob"},
+			},
+		},
+		{
+			desc: "unsupported_payment_method",
+			form: url.Values{
+				"player0":  []string{"alice"},
+				"methods0": []string{"cheque"},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "constraints",
+			form: url.Values{
+				"player0":  []string{"alice"},
+				"avoids0":  []string{" bob "},
+				"prefers0": []string{"charlie", ""},
+				"player1":  []string{"bob"},
+			},
+			want: Players{
+				{Name: "alice", Avoids: []string{"bob"}, Prefers: []string{"charlie"}},
+				{Name: "bob"},
+			},
+		},
+		{
+			desc: "paid_debts",
+			form: url.Values{
+				"player0": []string{"alice"},
+				"paid0":   []string{" bob : 5", "bob: 5.00", ""},
+				"player1": []string{"bob"},
+			},
+			want: Players{
+				{Name: "alice", Paid: []string{"bob: 5.00"}},
+				{Name: "bob"},
+			},
+		},
+		{
+			desc: "invalid_paid_debt",
+			form: url.Values{
+				"player0": []string{"alice"},
+				"paid0":   []string{"bob"},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "bank_details",
+			form: url.Values{
+				"player0":  []string{"alice"},
+				"iban0":    []string{"ch93 0076 2011 6238 5295 7"},
+				"address0": []string{" 8000  Zürich "},
+			},
+			want: Players{{Name: "alice", IBAN: "CH9300762011623852957", Address: "8000 Zürich"}},
+		},
+		{
+			desc: "invalid_iban",
+			form: url.Values{
+				"player0": []string{"alice"},
+				"iban0":   []string{"CH9300762011623852958"},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "invalid_amounts",
+			form: url.Values{
+				"player0": []string{"alice"},
+				"buyin0":  []string{"abc"},
+				"stack0":  []string{"-5"},
+				"player1": []string{"bob"},
+				"buyin1":  []string{"1.00005"},
+			},
+			wantErr: true,
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
-			got, err := FromForm(c.form)
+			got, err := FromForm(c.form, language.English)
 			if err != nil && !c.wantErr {
 				t.Fatalf("FromForm() returned an error: %v", err)
 			}
@@ -180,6 +277,308 @@ func TestFromForm(t *testing.T) {
 	}
 }
 
+// TestFromFormFieldErrors tests that FromForm() reports which fields are invalid.
+func TestFromFormFieldErrors(t *testing.T) {
+	form := url.Values{
+		"player0": []string{"alice"},
+		"buyin0":  []string{"abc"},
+		"stack0":  []string{"10"},
+		"player1": []string{"bob"},
+		"buyin1":  []string{"1.00005"},
+		"stack1":  []string{"-5"},
+		"iban1":   []string{"DE00"},
+	}
+	want := FieldErrors{
+		"buyin0": errorf("must be a number"),
+		"buyin1": errorf("must not have more than %d decimals", maxDecimals),
+		"stack1": errorf("must not be negative"),
+		"iban1":  errorf("is not a valid IBAN"),
+	}
+	_, err := FromForm(form, language.English)
+	got, ok := err.(FieldErrors)
+	if !ok {
+		t.Fatalf("FromForm() returned %v, want FieldErrors", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("FromForm() errors mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestFromFormDuplicateName tests that a duplicate name, or a variant of it, is
+// reported on the later row, which is the new one, whatever the order of the
+// map iteration.
+func TestFromFormDuplicateName(t *testing.T) {
+	cases := []struct {
+		desc string
+		form url.Values
+		want FieldErrors
+	}{
+		{
+			desc: "same_name",
+			form: url.Values{"player2": {"alice"}, "player1": {"bob"}, "player10": {"alice"}},
+			want: FieldErrors{"player10": errorf("duplicate player with name %q", "alice")},
+		},
+		{
+			desc: "variant",
+			form: url.Values{"player0": {"Zoë"}, "player1": {"bob"}, "player2": {" zoe "}},
+			want: FieldErrors{"player2": errorf("duplicate player with name %q, which is the same as %q", "zoe", "Zoë")},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			for i := 0; i < 50; i++ {
+				_, err := FromForm(c.form, language.English)
+				if diff := cmp.Diff(c.want, err); diff != "" {
+					t.Fatalf("FromForm() errors mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	p := Players{{Name: "alice", BuyIn: Cents(1000)}}
+	cases := []struct {
+		desc    string
+		names   []string
+		want    Players
+		wantErr bool
+	}{
+		{
+			desc:  "several",
+			names: []string{"bob", " charlie ", ""},
+			want:  Players{{Name: "alice", BuyIn: Cents(1000)}, {Name: "bob"}, {Name: "charlie"}},
+		},
+		{
+			desc:  "none",
+			names: nil,
+			want:  Players{{Name: "alice", BuyIn: Cents(1000)}},
+		},
+		{
+			desc:    "existing_player",
+			names:   []string{"bob", "Alice"},
+			wantErr: true,
+		},
+		{
+			desc:    "duplicate_name",
+			names:   []string{"bob", "BOB"},
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got, err := p.Add(c.names...)
+			if err != nil && !c.wantErr {
+				t.Fatalf("Players.Add() returned an error: %v", err)
+			}
+			if err == nil && c.wantErr {
+				t.Fatalf("Players.Add() didn't return an error, but one was expected")
+			}
+			if c.wantErr {
+				return
+			}
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Players.Add() mismatch (-want +got):\n%s", diff)
+			}
+			if len(p) != 1 {
+				t.Errorf("Players.Add() modified the original players")
+			}
+		})
+	}
+}
+
+func TestRebuy(t *testing.T) {
+	p := Players{{Name: "alice", BuyIn: Cents(2000)}, {Name: "bob", BuyIn: Cents(2000)}}
+	got, err := p.Rebuy("ALICE", Cents(2000))
+	if err != nil {
+		t.Fatalf("Players.Rebuy() returned an error: %v", err)
+	}
+	want := Players{{Name: "alice", BuyIn: Cents(4000)}, {Name: "bob", BuyIn: Cents(2000)}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Players.Rebuy() mismatch (-want +got):\n%s", diff)
+	}
+	if !p[0].BuyIn.Equal(Cents(2000)) {
+		t.Errorf("Players.Rebuy() modified the original players")
+	}
+	if _, err := p.Rebuy("charlie", Cents(2000)); err == nil {
+		t.Errorf("Players.Rebuy() of an unknown player didn't return an error, but one was expected")
+	}
+	if _, err := p.Rebuy("alice", Money{}); err == nil {
+		t.Errorf("Players.Rebuy() of zero didn't return an error, but one was expected")
+	}
+}
+
+func TestRename(t *testing.T) {
+	p := Players{{Name: "alice", BuyIn: Cents(1000), Stack: Cents(500)}, {Name: "bob", BuyIn: Cents(1500), Avoids: []string{"alice"}, Paid: []string{"alice: 5.00"}}}
+	cases := []struct {
+		desc       string
+		oldName    string
+		newName    string
+		want       Players
+		wantChange Change
+		wantErr    bool
+	}{
+		{
+			desc:       "rename",
+			oldName:    "alice",
+			newName:    "Alice",
+			want:       Players{{Name: "Alice", BuyIn: Cents(1000), Stack: Cents(500)}, {Name: "bob", BuyIn: Cents(1500), Avoids: []string{"Alice"}, Paid: []string{"Alice: 5.00"}}},
+			wantChange: Change{Player: "alice", Field: FieldName, Old: "alice", New: "Alice"},
+		},
+		{
+			desc:       "variant_of_old_name",
+			oldName:    "ALICE",
+			newName:    "Alicia",
+			want:       Players{{Name: "Alicia", BuyIn: Cents(1000), Stack: Cents(500)}, {Name: "bob", BuyIn: Cents(1500), Avoids: []string{"Alicia"}, Paid: []string{"Alicia: 5.00"}}},
+			wantChange: Change{Player: "alice", Field: FieldName, Old: "alice", New: "Alicia"},
+		},
+		{
+			desc:    "unknown_player",
+			oldName: "charlie",
+			newName: "Charlie",
+			wantErr: true,
+		},
+		{
+			desc:    "empty_name",
+			oldName: "alice",
+			newName: " ",
+			wantErr: true,
+		},
+		{
+			desc:    "duplicate_name",
+			oldName: "alice",
+			newName: "bob",
+			wantErr: true,
+		},
+		{
+			desc:    "duplicate_name_variant",
+			oldName: "alice",
+			newName: "Böb",
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got, gotChange, err := p.Rename(c.oldName, c.newName)
+			if err != nil && !c.wantErr {
+				t.Fatalf("Players.Rename() returned an error: %v", err)
+			}
+			if err == nil && c.wantErr {
+				t.Fatalf("Players.Rename() didn't return an error, but one was expected")
+			}
+			if c.wantErr {
+				return
+			}
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("Players.Rename() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(c.wantChange, gotChange); diff != "" {
+				t.Errorf("Players.Rename() change mismatch (-want +got):\n%s", diff)
+			}
+			if p[0].Name != "alice" || p[1].Avoids[0] != "alice" || p[1].Paid[0] != "alice: 5.00" {
+				t.Errorf("Players.Rename() modified the original players")
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	p := Players{{Name: "alice", BuyIn: Cents(1000)}, {Name: "bob", BuyIn: Cents(1500), Prefers: []string{"alice"}, Paid: []string{"alice: 5.00"}}}
+	got, err := p.Remove("alice")
+	if err != nil {
+		t.Fatalf("Players.Remove() returned an error: %v", err)
+	}
+	if diff := cmp.Diff(Players{{Name: "bob", BuyIn: Cents(1500)}}, got); diff != "" {
+		t.Errorf("Players.Remove() mismatch (-want +got):\n%s", diff)
+	}
+	if len(p) != 2 || p[0].Name != "alice" || len(p[1].Prefers) != 1 {
+		t.Errorf("Players.Remove() modified the original players")
+	}
+	if _, err := p.Remove("charlie"); err == nil {
+		t.Errorf("Players.Remove() of an unknown player didn't return an error, but one was expected")
+	}
+}
+
+func TestConstrain(t *testing.T) {
+	p := Players{{Name: "alice", Prefers: []string{"charlie"}}, {Name: "bob"}, {Name: "charlie"}}
+	cases := []struct {
+		desc    string
+		name    string
+		field   string
+		other   string
+		want    Players
+		wantErr bool
+	}{
+		{
+			desc:  "avoid",
+			name:  "alice",
+			field: FieldAvoids,
+			other: "BOB",
+			want:  Players{{Name: "alice", Avoids: []string{"bob"}, Prefers: []string{"charlie"}}, {Name: "bob"}, {Name: "charlie"}},
+		},
+		{
+			desc:  "prefer_again",
+			name:  "alice",
+			field: FieldPrefers,
+			other: "charlie",
+			want:  p,
+		},
+		{
+			desc:    "avoid_preferred_player",
+			name:    "charlie",
+			field:   FieldAvoids,
+			other:   "alice",
+			wantErr: true,
+		},
+		{
+			desc:    "themselves",
+			name:    "bob",
+			field:   FieldPrefers,
+			other:   "bob",
+			wantErr: true,
+		},
+		{
+			desc:    "unknown_player",
+			name:    "bob",
+			field:   FieldPrefers,
+			other:   "dan",
+			wantErr: true,
+		},
+		{
+			desc:    "unknown_field",
+			name:    "alice",
+			field:   FieldStack,
+			other:   "bob",
+			wantErr: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got, err := p.Constrain(c.name, c.field, c.other)
+			if err != nil && !c.wantErr {
+				t.Fatalf("Players.Constrain() returned an error: %v", err)
+			}
+			if err == nil && c.wantErr {
+				t.Fatalf("Players.Constrain() didn't return an error, but one was expected")
+			}
+			if c.wantErr {
+				return
+			}
+			if diff := cmp.Diff(c.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Players.Constrain() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	got, err := p.Unconstrain("alice", FieldPrefers, "charlie")
+	if err != nil {
+		t.Fatalf("Players.Unconstrain() returned an error: %v", err)
+	}
+	if diff := cmp.Diff(Players{{Name: "alice"}, {Name: "bob"}, {Name: "charlie"}}, got, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Players.Unconstrain() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestCalculateDebts(t *testing.T) {
 	cases := []struct {
 		desc    string
@@ -189,80 +588,80 @@ func TestCalculateDebts(t *testing.T) {
 	}{
 		{
 			desc:    "single_player",
-			players: Players{{Name: "alice", BuyIn: 500, Stack: 500}},
+			players: Players{{Name: "alice", BuyIn: Cents(500), Stack: Cents(500)}},
 		},
 		{
 			desc: "all_players_equality",
 			players: Players{
-				{Name: "alice", BuyIn: 500, Stack: 500},
-				{Name: "bob", BuyIn: 1500, Stack: 1500},
-				{Name: "charlie", BuyIn: 2000, Stack: 2000},
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(500)},
+				{Name: "bob", BuyIn: Cents(1500), Stack: Cents(1500)},
+				{Name: "charlie", BuyIn: Cents(2000), Stack: Cents(2000)},
 			},
 		},
 		{
 			desc: "two_players",
 			players: Players{
-				{Name: "alice", BuyIn: 500, Stack: 1000},
-				{Name: "bob", BuyIn: 500, Stack: 0},
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(1000)},
+				{Name: "bob", BuyIn: Cents(500), Stack: Cents(0)},
 			},
 			want: Debts{
-				"bob": []Debt{{Creditor: "alice", Amount: 500}},
+				"bob": []Debt{{Creditor: "alice", Amount: Cents(500)}},
 			},
 		},
 		{
 			desc: "two_loosers",
 			players: Players{
-				{Name: "alice", BuyIn: 500, Stack: 1200},
-				{Name: "bob", BuyIn: 500, Stack: 0},
-				{Name: "charlie", BuyIn: 1000, Stack: 800},
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(1200)},
+				{Name: "bob", BuyIn: Cents(500), Stack: Cents(0)},
+				{Name: "charlie", BuyIn: Cents(1000), Stack: Cents(800)},
 			},
 			want: Debts{
-				"bob":     []Debt{{Creditor: "alice", Amount: 500}},
-				"charlie": []Debt{{Creditor: "alice", Amount: 200}},
+				"bob":     []Debt{{Creditor: "alice", Amount: Cents(500)}},
+				"charlie": []Debt{{Creditor: "alice", Amount: Cents(200)}},
 			},
 		},
 		{
 			desc: "two_winners",
 			players: Players{
-				{Name: "alice", BuyIn: 1000, Stack: 1100},
-				{Name: "bob", BuyIn: 500, Stack: 900},
-				{Name: "charlie", BuyIn: 1000, Stack: 500},
+				{Name: "alice", BuyIn: Cents(1000), Stack: Cents(1100)},
+				{Name: "bob", BuyIn: Cents(500), Stack: Cents(900)},
+				{Name: "charlie", BuyIn: Cents(1000), Stack: Cents(500)},
 			},
 			want: Debts{
 				"charlie": []Debt{
-					{Creditor: "alice", Amount: 100},
-					{Creditor: "bob", Amount: 400},
+					{Creditor: "bob", Amount: Cents(400)},
+					{Creditor: "alice", Amount: Cents(100)},
 				},
 			},
 		},
 		{
 			desc: "two_winners_three_loosers",
 			players: Players{
-				{Name: "alice", BuyIn: 1000, Stack: 1350},
-				{Name: "bob", BuyIn: 500, Stack: 1050},
-				{Name: "charlie", BuyIn: 1000, Stack: 500},
-				{Name: "dan", BuyIn: 300, Stack: 0},
-				{Name: "eve", BuyIn: 700, Stack: 600},
+				{Name: "alice", BuyIn: Cents(1000), Stack: Cents(1350)},
+				{Name: "bob", BuyIn: Cents(500), Stack: Cents(1050)},
+				{Name: "charlie", BuyIn: Cents(1000), Stack: Cents(500)},
+				{Name: "dan", BuyIn: Cents(300), Stack: Cents(0)},
+				{Name: "eve", BuyIn: Cents(700), Stack: Cents(600)},
 			},
 			want: Debts{
 				"charlie": []Debt{
-					{Creditor: "alice", Amount: 350},
-					{Creditor: "bob", Amount: 150},
+					{Creditor: "alice", Amount: Cents(350)},
+					{Creditor: "bob", Amount: Cents(150)},
 				},
 				"dan": []Debt{
-					{Creditor: "bob", Amount: 300},
+					{Creditor: "bob", Amount: Cents(300)},
 				},
 				"eve": []Debt{
-					{Creditor: "bob", Amount: 100},
+					{Creditor: "bob", Amount: Cents(100)},
 				},
 			},
 		},
 		{
 			desc: "buy_in_and_stack_mismatch",
 			players: Players{
-				{Name: "alice", BuyIn: 500, Stack: 1200},
-				{Name: "bob", BuyIn: 1500, Stack: 500},
-				{Name: "charlie", BuyIn: 1000, Stack: 2000},
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(1200)},
+				{Name: "bob", BuyIn: Cents(1500), Stack: Cents(500)},
+				{Name: "charlie", BuyIn: Cents(1000), Stack: Cents(2000)},
 			},
 			wantErr: true,
 		},
@@ -281,13 +680,254 @@ func TestCalculateDebts(t *testing.T) {
 			if c.wantErr {
 				return
 			}
-			if diff := cmp.Diff(c.want, got, cmpopts.EquateEmpty(), sortDebt); diff != "" {
+			if diff := cmp.Diff(c.want, got, cmpopts.EquateEmpty()); diff != "" {
 				t.Errorf("Players.CalculateDebts() mismatch (-want +got):\n%s", diff)
 			}
 		})
 	}
 }
 
+func TestSettle(t *testing.T) {
+	cases := []struct {
+		desc            string
+		players         Players
+		unit            Money
+		want            Debts
+		wantAdjustments map[string]Money
+		wantErr         bool
+	}{
+		{
+			desc: "no_rounding_needed",
+			players: Players{
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(1000)},
+				{Name: "bob", BuyIn: Cents(500), Stack: Cents(0)},
+			},
+			unit: Cents(50),
+			want: Debts{
+				"bob": []Debt{{Creditor: "alice", Amount: Cents(500)}},
+			},
+		},
+		{
+			desc: "largest_remainder_rounded_up",
+			players: Players{
+				{Name: "alice", BuyIn: Cents(1000), Stack: Cents(2337)},
+				{Name: "bob", BuyIn: Cents(1000), Stack: Cents(363)},
+				{Name: "charlie", BuyIn: Cents(1000), Stack: Cents(300)},
+			},
+			unit: Cents(50),
+			want: Debts{
+				"bob":     []Debt{{Creditor: "alice", Amount: Cents(650)}},
+				"charlie": []Debt{{Creditor: "alice", Amount: Cents(700)}},
+			},
+			wantAdjustments: map[string]Money{
+				"alice": Cents(13),
+				"bob":   Cents(-13),
+			},
+		},
+		{
+			desc: "equal_remainders_rounded_up_by_name",
+			players: Players{
+				{Name: "bob", BuyIn: Cents(1000), Stack: Cents(750)},
+				{Name: "alice", BuyIn: Cents(1000), Stack: Cents(1250)},
+			},
+			unit: Cents(500),
+			want: Debts{
+				"bob": []Debt{{Creditor: "alice", Amount: Cents(500)}},
+			},
+			wantAdjustments: map[string]Money{
+				"alice": Cents(250),
+				"bob":   Cents(-250),
+			},
+		},
+		{
+			desc: "shared_payment_method_preferred",
+			players: Players{
+				{Name: "alice", BuyIn: Cents(1000), Stack: Cents(2000), Methods: []string{MethodMobile}},
+				{Name: "bob", BuyIn: Cents(1000), Stack: Cents(1500), Methods: []string{MethodCash}},
+				{Name: "charlie", BuyIn: Cents(1000), Stack: Cents(500), Methods: []string{MethodCash}},
+				{Name: "dan", BuyIn: Cents(1000), Stack: Cents(0)},
+			},
+			want: Debts{
+				"charlie": []Debt{{Creditor: "bob", Amount: Cents(500)}},
+				"dan":     []Debt{{Creditor: "alice", Amount: Cents(1000)}},
+			},
+		},
+		{
+			desc: "no_shared_payment_method",
+			players: Players{
+				{Name: "alice", BuyIn: Cents(1000), Stack: Cents(2000), Methods: []string{MethodMobile}},
+				{Name: "bob", BuyIn: Cents(1000), Stack: Cents(0), Methods: []string{MethodCash}},
+			},
+			want: Debts{
+				"bob": []Debt{{Creditor: "alice", Amount: Cents(1000), Unmatched: true}},
+			},
+		},
+		{
+			desc: "avoided_players",
+			players: Players{
+				{Name: "alice", BuyIn: Cents(1000), Stack: Cents(2000)},
+				{Name: "bob", BuyIn: Cents(1000), Stack: Cents(1500)},
+				{Name: "charlie", BuyIn: Cents(1000), Stack: Cents(500), Avoids: []string{"bob"}},
+				{Name: "dan", BuyIn: Cents(1000), Stack: Cents(0)},
+			},
+			want: Debts{
+				"charlie": []Debt{{Creditor: "alice", Amount: Cents(500)}},
+				"dan": []Debt{
+					{Creditor: "alice", Amount: Cents(500)},
+					{Creditor: "bob", Amount: Cents(500)},
+				},
+			},
+		},
+		{
+			desc: "avoided_players_need_a_detour",
+			players: Players{
+				{Name: "alice", BuyIn: Cents(1000), Stack: Cents(2500)},
+				{Name: "bob", BuyIn: Cents(1000), Stack: Cents(2000)},
+				{Name: "charlie", BuyIn: Cents(2000), Stack: Cents(500), Avoids: []string{"bob"}},
+				{Name: "dan", BuyIn: Cents(1000), Stack: Cents(0)},
+			},
+			// Dan would pay alice if charlie didn't avoid bob.
+			want: Debts{
+				"charlie": []Debt{{Creditor: "alice", Amount: Cents(1500)}},
+				"dan":     []Debt{{Creditor: "bob", Amount: Cents(1000)}},
+			},
+		},
+		{
+			desc: "preferred_players",
+			players: Players{
+				{Name: "alice", BuyIn: Cents(1000), Stack: Cents(2000)},
+				{Name: "bob", BuyIn: Cents(1000), Stack: Cents(1500), Prefers: []string{"charlie"}},
+				{Name: "charlie", BuyIn: Cents(1000), Stack: Cents(500)},
+				{Name: "dan", BuyIn: Cents(1000), Stack: Cents(0)},
+			},
+			want: Debts{
+				"charlie": []Debt{{Creditor: "bob", Amount: Cents(500)}},
+				"dan":     []Debt{{Creditor: "alice", Amount: Cents(1000)}},
+			},
+		},
+		{
+			desc: "impossible_constraints",
+			players: Players{
+				{Name: "alice", BuyIn: Cents(1000), Stack: Cents(2000)},
+				{Name: "bob", BuyIn: Cents(1000), Stack: Cents(0), Avoids: []string{"alice"}},
+			},
+			wantErr: true,
+		},
+		{
+			desc: "paid_debts",
+			players: Players{
+				{Name: "alice", BuyIn: Cents(1000), Stack: Cents(2000)},
+				{Name: "bob", BuyIn: Cents(1000), Stack: Cents(500), Paid: []string{"alice: 5.00"}},
+				// The amount of the debt changed since it was paid.
+				{Name: "charlie", BuyIn: Cents(1000), Stack: Cents(500), Paid: []string{"alice: 4.00"}},
+			},
+			want: Debts{
+				"bob":     []Debt{{Creditor: "alice", Amount: Cents(500), Paid: true}},
+				"charlie": []Debt{{Creditor: "alice", Amount: Cents(500)}},
+			},
+		},
+		{
+			// The debt is marked as paid by its amount before rounding, so it
+			// isn't rounded.
+			desc: "paid_rounded_debts",
+			players: Players{
+				{Name: "alice", BuyIn: Cents(1000), Stack: Cents(1637)},
+				{Name: "bob", BuyIn: Cents(1000), Stack: Cents(363), Paid: []string{"alice: 6.37"}},
+			},
+			unit: Cents(50),
+			want: Debts{
+				"bob": []Debt{{Creditor: "alice", Amount: Cents(637), Paid: true}},
+			},
+		},
+		{
+			// Rounded, b owes 1.00 to a and c owes 1.00 to d. Without rounding,
+			// b owes 0.80 to d, and c owes 0.80 to a and 0.20 to d. Once c paid
+			// a, only the remaining balances are rounded.
+			desc: "paid_debt_not_matching_rounded_debts",
+			players: Players{
+				{Name: "a", BuyIn: Cents(40), Stack: Cents(120)},
+				{Name: "b", BuyIn: Cents(290), Stack: Cents(210)},
+				{Name: "c", BuyIn: Cents(130), Stack: Cents(30), Paid: []string{"a: 0.80"}},
+				{Name: "d", BuyIn: Cents(210), Stack: Cents(310)},
+			},
+			unit: Cents(100),
+			want: Debts{
+				"b": []Debt{{Creditor: "d", Amount: Cents(100)}},
+				"c": []Debt{{Creditor: "a", Amount: Cents(80), Paid: true}},
+			},
+			wantAdjustments: map[string]Money{"b": Cents(-20), "c": Cents(20)},
+		},
+		{
+			desc: "negative_unit",
+			players: Players{
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(1000)},
+				{Name: "bob", BuyIn: Cents(500), Stack: Cents(0)},
+			},
+			unit:    Cents(-50),
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got, err := c.players.Settle(SettleOptions{Unit: c.unit})
+			if err != nil && !c.wantErr {
+				t.Fatalf("Players.Settle() returned an error: %v", err)
+			}
+			if err == nil && c.wantErr {
+				t.Fatalf("Players.Settle() didn't return an error, but one was expected")
+			}
+			if c.wantErr {
+				return
+			}
+			if diff := cmp.Diff(c.want, got.Debts, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Players.Settle() debts mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(c.wantAdjustments, got.Adjustments, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Players.Settle() adjustments mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// TestSettleDeterministic tests that the debts don't depend on the order of
+// the players, even when several of them have the same balance.
+func TestSettleDeterministic(t *testing.T) {
+	p := Players{
+		{Name: "alice", BuyIn: Cents(1000), Stack: Cents(1500)},
+		{Name: "bob", BuyIn: Cents(1000), Stack: Cents(1500)},
+		{Name: "Émile", BuyIn: Cents(1000), Stack: Cents(1500)},
+		{Name: "charlie", BuyIn: Cents(1000), Stack: Cents(500)},
+		{Name: "dan", BuyIn: Cents(1000), Stack: Cents(500)},
+		{Name: "eve", BuyIn: Cents(1000), Stack: Cents(500)},
+	}
+	want, err := p.Settle(SettleOptions{Unit: Cents(200)})
+	if err != nil {
+		t.Fatalf("Players.Settle() returned an error: %v", err)
+	}
+	for _, order := range [][]int{{5, 4, 3, 2, 1, 0}, {3, 0, 4, 1, 5, 2}, {2, 5, 1, 4, 0, 3}} {
+		var shuffled Players
+		for _, i := range order {
+			shuffled = append(shuffled, p[i])
+		}
+		got, err := shuffled.Settle(SettleOptions{Unit: Cents(200)})
+		if err != nil {
+			t.Fatalf("Players.Settle() returned an error: %v", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Players.Settle() of the players in the order %v mismatch (-want +got):\n%s", order, diff)
+		}
+	}
+}
+
+func TestDebtors(t *testing.T) {
+	d := Debts{"eve": nil, "Émile": nil, "bob": nil, "alice": nil}
+	want := []string{"alice", "bob", "Émile", "eve"}
+	if diff := cmp.Diff(want, d.Debtors(language.French)); diff != "" {
+		t.Errorf("Debts.Debtors() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestWinnersAndLoosers(t *testing.T) {
 	cases := []struct {
 		desc        string
@@ -300,46 +940,46 @@ func TestWinnersAndLoosers(t *testing.T) {
 		},
 		{
 			desc:    "one_player_at_zero",
-			players: Players{{Name: "alice", BuyIn: 1500, Stack: 1500}},
+			players: Players{{Name: "alice", BuyIn: Cents(1500), Stack: Cents(1500)}},
 			wantWinners: Players{
-				{Name: "alice", BuyIn: 1500, Stack: 1500},
+				{Name: "alice", BuyIn: Cents(1500), Stack: Cents(1500)},
 			},
 		},
 		{
 			desc: "winners",
 			players: Players{
-				{Name: "alice", BuyIn: 1500, Stack: 3000},
-				{Name: "bob", BuyIn: 250, Stack: 1000},
+				{Name: "alice", BuyIn: Cents(1500), Stack: Cents(3000)},
+				{Name: "bob", BuyIn: Cents(250), Stack: Cents(1000)},
 			},
 			wantWinners: Players{
-				{Name: "alice", BuyIn: 1500, Stack: 3000},
-				{Name: "bob", BuyIn: 250, Stack: 1000},
+				{Name: "alice", BuyIn: Cents(1500), Stack: Cents(3000)},
+				{Name: "bob", BuyIn: Cents(250), Stack: Cents(1000)},
 			},
 		},
 		{
 			desc: "loosers",
 			players: Players{
-				{Name: "alice", BuyIn: 500, Stack: 100},
-				{Name: "bob", BuyIn: 8000, Stack: 4000},
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(100)},
+				{Name: "bob", BuyIn: Cents(8000), Stack: Cents(4000)},
 			},
 			wantLoosers: Players{
-				{Name: "alice", BuyIn: 500, Stack: 100},
-				{Name: "bob", BuyIn: 8000, Stack: 4000},
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(100)},
+				{Name: "bob", BuyIn: Cents(8000), Stack: Cents(4000)},
 			},
 		},
 		{
 			desc: "winners_and_loosers",
 			players: Players{
-				{Name: "alice", BuyIn: 500, Stack: 100},
-				{Name: "bob", BuyIn: 8000, Stack: 4000},
-				{Name: "charlie", BuyIn: 5000, Stack: 6000},
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(100)},
+				{Name: "bob", BuyIn: Cents(8000), Stack: Cents(4000)},
+				{Name: "charlie", BuyIn: Cents(5000), Stack: Cents(6000)},
 			},
 			wantWinners: Players{
-				{Name: "charlie", BuyIn: 5000, Stack: 6000},
+				{Name: "charlie", BuyIn: Cents(5000), Stack: Cents(6000)},
 			},
 			wantLoosers: Players{
-				{Name: "alice", BuyIn: 500, Stack: 100},
-				{Name: "bob", BuyIn: 8000, Stack: 4000},
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(100)},
+				{Name: "bob", BuyIn: Cents(8000), Stack: Cents(4000)},
 			},
 		},
 	}
@@ -361,38 +1001,36 @@ func TestPlayersBuyInAndStack(t *testing.T) {
 	cases := []struct {
 		desc      string
 		players   Players
-		wantBuyIn int
-		wantStack int
+		wantBuyIn Money
+		wantStack Money
 	}{
 		{
-			desc:      "no_players",
-			wantBuyIn: 0,
-			wantStack: 0,
+			desc: "no_players",
 		},
 		{
 			desc:      "one_player",
-			players:   Players{{Name: "alice", BuyIn: 1500, Stack: 3000}},
-			wantBuyIn: 1500,
-			wantStack: 3000,
+			players:   Players{{Name: "alice", BuyIn: Cents(1500), Stack: Cents(3000)}},
+			wantBuyIn: Cents(1500),
+			wantStack: Cents(3000),
 		},
 		{
 			desc: "two_players",
 			players: Players{
-				{Name: "alice", BuyIn: 1500, Stack: 3000},
-				{Name: "bob", BuyIn: 250, Stack: 1000}},
-			wantBuyIn: 1750,
-			wantStack: 4000,
+				{Name: "alice", BuyIn: Cents(1500), Stack: Cents(3000)},
+				{Name: "bob", BuyIn: Cents(250), Stack: Cents(1000)}},
+			wantBuyIn: Cents(1750),
+			wantStack: Cents(4000),
 		},
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
 			gotBuyIn := c.players.BuyIn()
-			if gotBuyIn != c.wantBuyIn {
-				t.Errorf("Players.BuyIn() = %d, want %d", gotBuyIn, c.wantBuyIn)
+			if !gotBuyIn.Equal(c.wantBuyIn) {
+				t.Errorf("Players.BuyIn() = %v, want %v", gotBuyIn, c.wantBuyIn)
 			}
 			gotStack := c.players.Stack()
-			if gotBuyIn != c.wantBuyIn {
-				t.Errorf("Players.Stack() = %d, want %d", gotStack, c.wantStack)
+			if !gotStack.Equal(c.wantStack) {
+				t.Errorf("Players.Stack() = %v, want %v", gotStack, c.wantStack)
 			}
 		})
 	}
@@ -410,40 +1048,56 @@ func TestBest(t *testing.T) {
 		},
 		// {
 		// 	desc:    "one_player_at_balance",
-		// 	players: Players{{Name: "alice", BuyIn: 1500, Stack: 1500}},
+		// 	players: Players{{Name: "alice", BuyIn: Cents(1500), Stack: Cents(1500)}},
 		// 	want:    nil,
 		// },
 		{
 			desc:    "one_player",
-			players: Players{{Name: "alice", BuyIn: 1500, Stack: 500}},
-			want:    &Player{Name: "alice", BuyIn: 1500, Stack: 500},
+			players: Players{{Name: "alice", BuyIn: Cents(1500), Stack: Cents(500)}},
+			want:    &Player{Name: "alice", BuyIn: Cents(1500), Stack: Cents(500)},
 		},
 		{
 			desc: "winners",
 			players: Players{
-				{Name: "alice", BuyIn: 1500, Stack: 3000},
-				{Name: "bob", BuyIn: 500, Stack: 5000},
-				{Name: "charlie", BuyIn: 5000, Stack: 6000},
+				{Name: "alice", BuyIn: Cents(1500), Stack: Cents(3000)},
+				{Name: "bob", BuyIn: Cents(500), Stack: Cents(5000)},
+				{Name: "charlie", BuyIn: Cents(5000), Stack: Cents(6000)},
 			},
-			want: &Player{Name: "bob", BuyIn: 500, Stack: 5000},
+			want: &Player{Name: "bob", BuyIn: Cents(500), Stack: Cents(5000)},
 		},
 		{
 			desc: "loosers",
 			players: Players{
-				{Name: "alice", BuyIn: 500, Stack: 100},
-				{Name: "bob", BuyIn: 8000, Stack: 4000},
-				{Name: "charlie", BuyIn: 3000, Stack: 2500},
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(100)},
+				{Name: "bob", BuyIn: Cents(8000), Stack: Cents(4000)},
+				{Name: "charlie", BuyIn: Cents(3000), Stack: Cents(2500)},
+			},
+			want: &Player{Name: "alice", BuyIn: Cents(500), Stack: Cents(100)},
+		},
+		{
+			desc: "tie_broken_by_name",
+			players: Players{
+				{Name: "bob", BuyIn: Cents(500), Stack: Cents(1000)},
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(1000)},
+			},
+			want: &Player{Name: "alice", BuyIn: Cents(500), Stack: Cents(1000)},
+		},
+		{
+			desc: "tie_broken_by_name",
+			players: Players{
+				{Name: "bob", BuyIn: Cents(500), Stack: Cents(1000)},
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(1000)},
 			},
-			want: &Player{Name: "alice", BuyIn: 500, Stack: 100},
+			want: &Player{Name: "alice", BuyIn: Cents(500), Stack: Cents(1000)},
 		},
 		{
 			desc: "winners_and_loosers",
 			players: Players{
-				{Name: "alice", BuyIn: 500, Stack: 100},
-				{Name: "bob", BuyIn: 8000, Stack: 4000},
-				{Name: "charlie", BuyIn: 5000, Stack: 6000},
+				{Name: "alice", BuyIn: Cents(500), Stack: Cents(100)},
+				{Name: "bob", BuyIn: Cents(8000), Stack: Cents(4000)},
+				{Name: "charlie", BuyIn: Cents(5000), Stack: Cents(6000)},
 			},
-			want: &Player{Name: "charlie", BuyIn: 5000, Stack: 6000},
+			want: &Player{Name: "charlie", BuyIn: Cents(5000), Stack: Cents(6000)},
 		},
 	}
 	for _, c := range cases {
@@ -0,0 +1,240 @@
+package players
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Settings are the settings of a game, which apply to all its players.
+type Settings struct {
+	// BuyIn is the standard amount players buy in for.
+	BuyIn Money `json:"b"`
+	// Currency is the ISO 4217 code of the currency the game is played in,
+	// e.g. "CHF".
+	Currency string `json:"c,omitempty"`
+	// Chips are the values of the chips in play, the smallest first.
+	Chips []Money `json:"k,omitempty"`
+}
+
+// IsZero returns whether none of the settings is set.
+func (s Settings) IsZero() bool {
+	return s.BuyIn.IsZero() && s.Currency == "" && len(s.Chips) == 0
+}
+
+// WithBuyIn returns a copy of the settings, which may be nil, with the
+// standard buy-in parsed from the value according to the conventions of the
+// language. The returned settings are nil if none of them is set.
+func (s *Settings) WithBuyIn(value string, tag language.Tag) (*Settings, error) {
+	buyIn, err := parseAmount(value, DecimalSeparator(tag))
+	if err != nil {
+		return nil, err
+	}
+	var ret Settings
+	if s != nil {
+		ret = *s
+		ret.Chips = append([]Money(nil), s.Chips...)
+	}
+	ret.BuyIn = buyIn
+	if ret.IsZero() {
+		return nil, nil
+	}
+	return &ret, nil
+}
+
+// Preset describes how the games of a group usually start: with the same
+// players and the same settings.
+type Preset struct {
+	// Names are the names of the players.
+	Names []string `json:"n,omitempty"`
+	Settings
+}
+
+// ToBase64 encodes the Preset in base64 URL-encoding. It can be decoded using
+// PresetFromBase64().
+func (p *Preset) ToBase64() (string, error) {
+	return encode(p)
+}
+
+// PresetFromBase64 decodes a Preset which was base64 URL-encoded using
+// Preset.ToBase64().
+func PresetFromBase64(data string) (*Preset, error) {
+	ret := &Preset{}
+	if err := decode(data, ret); err != nil {
+		return nil, err
+	}
+	if err := ret.normalize(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ReadPresets reads presets encoded in JSON, as an object mapping the name of
+// each preset to its value, e.g.:
+//
+//	{"thursday": {"names": ["alice", "bob"], "buyin": "20", "currency": "CHF", "chips": ["0.5", "1", "5"]}}
+//
+// The amounts must be given as strings. Unlike the presets encoded in URLs,
+// the keys are spelled out, as the file is written by hand.
+func ReadPresets(r io.Reader) (map[string]*Preset, error) {
+	var file map[string]*struct {
+		Names    []string `json:"names"`
+		BuyIn    Money    `json:"buyin"`
+		Currency string   `json:"currency"`
+		Chips    []Money  `json:"chips"`
+	}
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, errorf("failed to decode JSON: %v", err)
+	}
+	ret := make(map[string]*Preset)
+	for name, f := range file {
+		if f == nil {
+			return nil, errorf("preset %q is empty", name)
+		}
+		p := &Preset{Names: f.Names, Settings: Settings{BuyIn: f.BuyIn, Currency: f.Currency, Chips: f.Chips}}
+		if err := p.normalize(); err != nil {
+			return nil, errorf("preset %q is invalid: %v", name, err)
+		}
+		ret[name] = p
+	}
+	return ret, nil
+}
+
+// PresetFromForm creates a Preset from an HTML form's data. It expects the
+// following fields: "names", the names of the players separated as expected
+// by SplitNames(), "buyin", the standard buy-in, "currency", and "chips", the
+// values of the chips separated by spaces or semicolons. The amounts are
+// parsed according to the conventions of the language. If any field is
+// invalid, the returned error is a FieldErrors.
+func PresetFromForm(form url.Values, tag language.Tag) (*Preset, error) {
+	decimalSep := DecimalSeparator(tag)
+	errs := make(FieldErrors)
+	ret := &Preset{Names: SplitNames(form.Get("names"))}
+	var err error
+	if ret.BuyIn, err = parseAmount(form.Get("buyin"), decimalSep); err != nil {
+		errs["buyin"] = err
+	}
+	ret.Currency = form.Get("currency")
+	for _, v := range strings.Fields(strings.ReplaceAll(form.Get("chips"), ";", " ")) {
+		chip, err := parseAmount(v, decimalSep)
+		if err != nil {
+			errs["chips"] = err
+			break
+		}
+		ret.Chips = append(ret.Chips, chip)
+	}
+	if err := ret.normalize(); err != nil {
+		for k, v := range err.(FieldErrors) {
+			if errs[k] == nil {
+				errs[k] = v
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return ret, nil
+}
+
+// normalize normalizes the names of the players, the currency code and the
+// values of the chips, which are sorted. If some of them are invalid, the
+// returned error is a FieldErrors.
+func (p *Preset) normalize() error {
+	errs := make(FieldErrors)
+	playerNames := make(names)
+	p.Names = normalizeNames(p.Names)
+	for _, name := range p.Names {
+		if err := playerNames.add(name); err != nil {
+			errs["names"] = err
+			break
+		}
+	}
+	if err := p.Settings.normalize(); err != nil {
+		for k, v := range err.(FieldErrors) {
+			errs[k] = v
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// normalize normalizes the currency code and the values of the chips, which
+// are sorted. If some of them are invalid, the returned error is a
+// FieldErrors.
+func (s *Settings) normalize() error {
+	errs := make(FieldErrors)
+	if s.BuyIn.Sign() < 0 {
+		errs["buyin"] = errorf("must not be negative")
+	}
+	s.Currency = strings.ToUpper(strings.TrimSpace(s.Currency))
+	if s.Currency != "" && (len(s.Currency) != 3 || strings.Trim(s.Currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "") {
+		errs["currency"] = errorf("must be a code of 3 letters, e.g. CHF")
+	}
+	var chips []Money
+	for _, chip := range s.Chips {
+		if chip.Sign() <= 0 {
+			errs["chips"] = errorf("the value of a chip must be positive")
+			break
+		}
+		if !containsMoney(chips, chip) {
+			chips = append(chips, chip)
+		}
+	}
+	sort.Slice(chips, func(i, j int) bool {
+		return chips[i].Cmp(chips[j]) < 0
+	})
+	s.Chips = chips
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// containsMoney returns whether the amounts contain the given one.
+func containsMoney(amounts []Money, m Money) bool {
+	for _, a := range amounts {
+		if a.Equal(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// Game returns a new game with the settings of the preset, whose players
+// already bought in for the standard amount. Its history is left to the
+// caller.
+func (p *Preset) Game() (*Game, error) {
+	ret, err := NewGame()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range p.Names {
+		ret.Players = append(ret.Players, &Player{Name: name, BuyIn: p.BuyIn})
+	}
+	if !p.Settings.IsZero() {
+		settings := p.Settings
+		settings.Chips = append([]Money(nil), p.Chips...)
+		ret.Settings = &settings
+	}
+	return ret, nil
+}
+
+// Preset returns a preset to start new games with the same players and
+// settings as this one.
+func (g *Game) Preset() *Preset {
+	ret := &Preset{}
+	for _, p := range g.Players {
+		ret.Names = append(ret.Names, p.Name)
+	}
+	if g.Settings != nil {
+		ret.Settings = *g.Settings
+		ret.Chips = append([]Money(nil), g.Settings.Chips...)
+	}
+	return ret
+}
@@ -0,0 +1,175 @@
+package players
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/text/language"
+)
+
+func TestPresetFromForm(t *testing.T) {
+	cases := []struct {
+		desc     string
+		form     url.Values
+		tag      language.Tag
+		want     *Preset
+		wantErrs []string
+	}{
+		{
+			desc: "all_fields",
+			form: url.Values{"names": {"alice\nBob, charlie"}, "buyin": {"20"}, "currency": {" chf"}, "chips": {"5 0.5;1  1"}},
+			tag:  language.English,
+			want: &Preset{
+				Names:    []string{"alice", "Bob", "charlie"},
+				Settings: Settings{BuyIn: Cents(2000), Currency: "CHF", Chips: []Money{Cents(50), Cents(100), Cents(500)}},
+			},
+		},
+		{
+			desc: "decimal_comma",
+			form: url.Values{"buyin": {"12,5"}, "chips": {"0,25 1"}},
+			tag:  language.French,
+			want: &Preset{Settings: Settings{BuyIn: Cents(1250), Chips: []Money{Cents(25), Cents(100)}}},
+		},
+		{
+			desc:     "invalid",
+			form:     url.Values{"names": {"alice, Alice"}, "buyin": {"-1"}, "currency": {"CH"}, "chips": {"1 0"}},
+			tag:      language.English,
+			wantErrs: []string{"buyin", "chips", "currency", "names"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got, err := PresetFromForm(c.form, c.tag)
+			if c.wantErrs != nil {
+				errs, ok := err.(FieldErrors)
+				if !ok {
+					t.Fatalf("PresetFromForm() returned %v, want FieldErrors", err)
+				}
+				var fields []string
+				for field := range errs {
+					fields = append(fields, field)
+				}
+				if diff := cmp.Diff(c.wantErrs, fields, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+					t.Errorf("PresetFromForm() invalid fields mismatch (-want +got):\n%s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("PresetFromForm() returned an error: %v", err)
+			}
+			if diff := cmp.Diff(c.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("PresetFromForm() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPresetBase64(t *testing.T) {
+	p := &Preset{Names: []string{"alice", "bob"}, Settings: Settings{BuyIn: Cents(2000), Currency: "CHF", Chips: []Money{Cents(50), Cents(100)}}}
+	b64, err := p.ToBase64()
+	if err != nil {
+		t.Fatalf("Preset.ToBase64() returned an error: %v", err)
+	}
+	got, err := PresetFromBase64(b64)
+	if err != nil {
+		t.Fatalf("PresetFromBase64() returned an error: %v", err)
+	}
+	if diff := cmp.Diff(p, got); diff != "" {
+		t.Errorf("base64 encoding/decoding mismatch (-want +got):\n%s", diff)
+	}
+	// The presets are encoded in URLs, so their keys are as short as the
+	// ones of the games.
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatalf("json.Marshal() returned an error: %v", err)
+	}
+	if want := `{"n":["alice","bob"],"b":"20","c":"CHF","k":["0.5","1"]}`; string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+	if _, err := PresetFromBase64("abc"); err == nil {
+		t.Errorf("PresetFromBase64() of invalid data didn't return an error, but one was expected")
+	}
+}
+
+func TestReadPresets(t *testing.T) {
+	got, err := ReadPresets(strings.NewReader(`{"thursday": {"names": ["alice", " bob "], "buyin": "20", "currency": "chf", "chips": ["1", "0.5"]}}`))
+	if err != nil {
+		t.Fatalf("ReadPresets() returned an error: %v", err)
+	}
+	want := map[string]*Preset{
+		"thursday": {Names: []string{"alice", "bob"}, Settings: Settings{BuyIn: Cents(2000), Currency: "CHF", Chips: []Money{Cents(50), Cents(100)}}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ReadPresets() mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, data := range []string{`[]`, `{"empty": null}`, `{"duplicate": {"names": ["alice", "ALICE"]}}`} {
+		if _, err := ReadPresets(strings.NewReader(data)); err == nil {
+			t.Errorf("ReadPresets(%s) didn't return an error, but one was expected", data)
+		}
+	}
+}
+
+func TestPresetGame(t *testing.T) {
+	p := &Preset{Names: []string{"alice", "bob"}, Settings: Settings{BuyIn: Cents(2000), Currency: "CHF"}}
+	g, err := p.Game()
+	if err != nil {
+		t.Fatalf("Preset.Game() returned an error: %v", err)
+	}
+	if g.ID == "" {
+		t.Errorf("Preset.Game() returned a game without ID")
+	}
+	want := Players{{Name: "alice", BuyIn: Cents(2000)}, {Name: "bob", BuyIn: Cents(2000)}}
+	if diff := cmp.Diff(want, g.Players); diff != "" {
+		t.Errorf("Preset.Game() players mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(&p.Settings, g.Settings, cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Preset.Game() settings mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(p, g.Preset(), cmpopts.EquateEmpty()); diff != "" {
+		t.Errorf("Game.Preset() mismatch (-want +got):\n%s", diff)
+	}
+
+	g, err = (&Preset{Names: []string{"alice"}}).Game()
+	if err != nil {
+		t.Fatalf("Preset.Game() returned an error: %v", err)
+	}
+	if g.Settings != nil {
+		t.Errorf("Preset.Game() of a preset without settings = %+v, want nil settings", g.Settings)
+	}
+}
+
+func TestSettingsWithBuyIn(t *testing.T) {
+	cases := []struct {
+		desc     string
+		settings *Settings
+		value    string
+		want     *Settings
+		wantErr  bool
+	}{
+		{desc: "no_settings", value: "20", want: &Settings{BuyIn: Cents(2000)}},
+		{desc: "no_settings_cleared", value: ""},
+		{desc: "keep_other_settings", settings: &Settings{BuyIn: Cents(2000), Currency: "CHF"}, value: "25.50", want: &Settings{BuyIn: Cents(2550), Currency: "CHF"}},
+		{desc: "cleared", settings: &Settings{BuyIn: Cents(2000), Currency: "CHF"}, value: "", want: &Settings{Currency: "CHF"}},
+		{desc: "all_cleared", settings: &Settings{BuyIn: Cents(2000)}, value: "0"},
+		{desc: "invalid", value: "abc", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got, err := c.settings.WithBuyIn(c.value, language.English)
+			if err != nil && !c.wantErr {
+				t.Fatalf("Settings.WithBuyIn() returned an error: %v", err)
+			}
+			if err == nil && c.wantErr {
+				t.Fatalf("Settings.WithBuyIn() didn't return an error, but one was expected")
+			}
+			if diff := cmp.Diff(c.want, got, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("Settings.WithBuyIn() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
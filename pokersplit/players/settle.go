@@ -0,0 +1,334 @@
+package players
+
+import (
+	"sort"
+	"strings"
+)
+
+// SettleOptions configures how the debts are settled.
+type SettleOptions struct {
+	// Unit is the granularity of the transfers, e.g. 0.50 or 5, so that they
+	// can be paid in cash. The zero value means the amounts aren't rounded.
+	Unit Money
+}
+
+// Settlement holds the result of Settle().
+type Settlement struct {
+	Debts Debts
+	// Adjustments maps the names of the players whose balance was rounded to
+	// the amount added to it, which is negative if they got less.
+	Adjustments map[string]Money
+}
+
+// Settle figures out who owes how much to whom. To limit the number of
+// transactions, the best looser (the player who lost the least) owes monney to
+// the best winner (the player who won the most). After each iteration, the
+// balances are updated and the best winner/looser are re-identified.
+//
+// The players who avoid each other never settle their debts together. The
+// best looser preferably owes money to the best winner with whom they share a
+// payment method, then to the one they prefer. Debts between players who
+// don't share a payment method are flagged as unmatched. A debt is only
+// settled if the remaining ones can still be settled without the pairs of
+// players who avoid each other; if they can't, the next best pair of players
+// is considered.
+//
+// If a unit is set, the balances are first rounded to multiples of it, so
+// that all the transfers are multiples of it too. The rounding is disclosed in
+// the Settlement's adjustments. The debts are marked as paid by their amount
+// without rounding, so the debts which were paid keep that amount, and only
+// the balances which remain once they're deducted are rounded and settled.
+func (p Players) Settle(opts SettleOptions) (*Settlement, error) {
+	if opts.Unit.IsZero() {
+		return p.settle(opts)
+	}
+	exact, err := p.settle(SettleOptions{})
+	if err != nil {
+		return nil, err
+	}
+	rest := p.Clone()
+	paid := make(Debts)
+	for debtor, debts := range exact.Debts {
+		for _, d := range debts {
+			if !d.Paid {
+				continue
+			}
+			paid[debtor] = append(paid[debtor], d)
+			looser, winner := rest[rest.find(debtor)], rest[rest.find(d.Creditor)]
+			looser.Stack = looser.Stack.Add(d.Amount)
+			winner.Stack = winner.Stack.Sub(d.Amount)
+		}
+	}
+	for _, player := range rest {
+		player.Paid = nil
+	}
+	ret, err := rest.settle(opts)
+	if err != nil {
+		return nil, err
+	}
+	for debtor, debts := range paid {
+		ret.Debts[debtor] = append(ret.Debts[debtor], debts...)
+		sortDebts(ret.Debts[debtor])
+	}
+	return ret, nil
+}
+
+// settle implements Settle(), marking the debts as paid by their amount
+// whatever the unit.
+func (p Players) settle(opts SettleOptions) (*Settlement, error) {
+	if !p.BuyIn().Equal(p.Stack()) {
+		return nil, errorf("the total of the buy-ins doesn't match the total of the stacks")
+	}
+	if opts.Unit.Sign() < 0 {
+		return nil, errorf("the rounding unit must not be negative")
+	}
+	ret := &Settlement{Debts: make(Debts)}
+	// Sort the players by name, so that the debts don't depend on the order
+	// of the players.
+	ordered := append(Players(nil), p...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return lessName(ordered[i].Name, ordered[j].Name)
+	})
+	winners, loosers := ordered.winnersAndLoosers()
+	if !opts.Unit.IsZero() {
+		ret.Adjustments = append(winners, loosers...).round(opts.Unit)
+	}
+	if stuck := unsettleable(winners, loosers); len(stuck) > 0 {
+		var stuckNames []string
+		for _, player := range stuck {
+			stuckNames = append(stuckNames, player.Name)
+		}
+		return nil, errorf("%s can't settle their debts without paying players they avoid", strings.Join(stuckNames, ", "))
+	}
+	// The algorithm modifies the stacks to keep track of the debts already
+	// taken into account. Once all winners have their stack equal to their
+	// buy-in, it means that all debts are settled.
+	for !winners.BuyIn().Equal(winners.Stack()) {
+		looser, winner := next(winners, loosers)
+		amount := looser.BuyIn.Sub(looser.Stack)
+		if gain := winner.Stack.Sub(winner.BuyIn); gain.Cmp(amount) < 0 {
+			amount = gain
+		}
+		looser.Stack = looser.Stack.Add(amount)
+		winner.Stack = winner.Stack.Sub(amount)
+		debt := Debt{
+			Creditor:  winner.Name,
+			Amount:    amount,
+			Unmatched: !looser.shares(winner),
+			Paid:      looser.hasPaid(winner.Name, amount),
+		}
+		ret.Debts[looser.Name] = append(ret.Debts[looser.Name], debt)
+	}
+	for _, debts := range ret.Debts {
+		sortDebts(debts)
+	}
+	return ret, nil
+}
+
+// sortDebts sorts the debts of a debtor from the largest to the smallest, the
+// debts of the same amount being sorted by creditor.
+func sortDebts(debts []Debt) {
+	sort.Slice(debts, func(i, j int) bool {
+		if c := debts[i].Amount.Cmp(debts[j].Amount); c != 0 {
+			return c > 0
+		}
+		return lessName(debts[i].Creditor, debts[j].Creditor)
+	})
+}
+
+// next returns the looser and the winner who settle the next debt. The
+// remaining debts must be settleable, which guarantees that such a pair
+// exists.
+func next(winners, loosers Players) (looser, winner *Player) {
+	for _, l := range byBalance(loosers) {
+		candidates := byBalance(winners)
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return rank(l, candidates[i]) < rank(l, candidates[j])
+		})
+		for _, w := range candidates {
+			if l.avoids(w) {
+				continue
+			}
+			// Settle the debt temporarily, to check whether the remaining ones
+			// can still be settled.
+			looserStack, winnerStack := l.Stack, w.Stack
+			amount := l.BuyIn.Sub(l.Stack)
+			if gain := w.Stack.Sub(w.BuyIn); gain.Cmp(amount) < 0 {
+				amount = gain
+			}
+			l.Stack, w.Stack = l.Stack.Add(amount), w.Stack.Sub(amount)
+			stuck := unsettleable(winners, loosers)
+			l.Stack, w.Stack = looserStack, winnerStack
+			if len(stuck) == 0 {
+				return l, w
+			}
+		}
+	}
+	// Unreachable: when the debts can be settled, they can be settled in such
+	// a way that a player settles with a single other player. That debt can be
+	// settled first without preventing the other ones from being settled.
+	panic("no debt can be settled")
+}
+
+// byBalance returns the players who have a non-zero balance, from the one who
+// won the most, or lost the least, to the one who won the least, or lost the
+// most. Players having the same balance are sorted by name, the first one
+// being the one returned by best().
+func byBalance(p Players) Players {
+	var ret Players
+	for _, player := range p {
+		if !player.BuyIn.Equal(player.Stack) {
+			ret = append(ret, player)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if c := ret[i].Stack.Sub(ret[i].BuyIn).Cmp(ret[j].Stack.Sub(ret[j].BuyIn)); c != 0 {
+			return c > 0
+		}
+		return lessName(ret[i].Name, ret[j].Name)
+	})
+	return ret
+}
+
+// rank returns how desirable it is for the looser to pay the winner, the
+// lowest being the most desirable. Sharing a payment method matters the most,
+// because the debt can't be paid otherwise.
+func rank(looser, winner *Player) int {
+	switch shares, prefers := looser.shares(winner), looser.prefers(winner); {
+	case shares && prefers:
+		return 0
+	case shares:
+		return 1
+	case prefers:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// unsettleable returns the loosers who can't pay their debts without paying
+// winners they avoid, or nil if all the debts can be settled.
+//
+// It computes the maximum flow of money from the loosers to the winners they
+// don't avoid, using the Edmonds-Karp algorithm. If the flow doesn't cover all
+// the debts, the loosers which are still reachable from the source can't pay
+// their debts: the winners they may pay aren't owed enough.
+func unsettleable(winners, loosers Players) Players {
+	var total Money
+	for _, l := range loosers {
+		total = total.Add(l.BuyIn.Sub(l.Stack))
+	}
+	// The source is the first node, followed by the loosers, the winners and
+	// the sink. The residual capacities are initially the capacities of the
+	// edges: the debts of the loosers, the gains of the winners and, between
+	// them, a capacity large enough not to limit the flow.
+	source, sink := 0, len(loosers)+len(winners)+1
+	winner := func(j int) int { return 1 + len(loosers) + j }
+	residual := make([][]Money, sink+1)
+	for i := range residual {
+		residual[i] = make([]Money, sink+1)
+	}
+	for i, l := range loosers {
+		residual[source][1+i] = l.BuyIn.Sub(l.Stack)
+		for j, w := range winners {
+			if !l.avoids(w) {
+				residual[1+i][winner(j)] = total
+			}
+		}
+	}
+	for j, w := range winners {
+		residual[winner(j)][sink] = w.Stack.Sub(w.BuyIn)
+	}
+
+	for {
+		prev := augmentingPath(residual, source)
+		if prev[sink] < 0 {
+			var ret Players
+			for i, l := range loosers {
+				if prev[1+i] >= 0 {
+					ret = append(ret, l)
+				}
+			}
+			return ret
+		}
+		bottleneck := total
+		for v := sink; v != source; v = prev[v] {
+			if c := residual[prev[v]][v]; c.Cmp(bottleneck) < 0 {
+				bottleneck = c
+			}
+		}
+		for v := sink; v != source; v = prev[v] {
+			residual[prev[v]][v] = residual[prev[v]][v].Sub(bottleneck)
+			residual[v][prev[v]] = residual[v][prev[v]].Add(bottleneck)
+		}
+	}
+}
+
+// augmentingPath does a breadth-first search of the nodes reachable from the
+// source through edges having a positive residual capacity. It returns the
+// node preceding each node in the shortest path from the source, or -1 if it
+// isn't reachable.
+func augmentingPath(residual [][]Money, source int) []int {
+	prev := make([]int, len(residual))
+	for i := range prev {
+		prev[i] = -1
+	}
+	prev[source] = source
+	queue := []int{source}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for v, c := range residual[u] {
+			if prev[v] < 0 && c.Sign() > 0 {
+				prev[v] = u
+				queue = append(queue, v)
+			}
+		}
+	}
+	return prev
+}
+
+// round rounds the balances of the players to multiples of the unit, by
+// modifying their stack, and returns the adjustments made to the balances.
+//
+// The balances are rounded using the largest remainder method, so that they
+// still add up to zero: they are all rounded down, then the ones with the
+// largest remainders are rounded up until the remainders are used up. Equal
+// remainders are rounded up in the order of the players' names.
+func (p Players) round(unit Money) map[string]Money {
+	type remainder struct {
+		player *Player
+		amount Money
+	}
+	var remainders []remainder
+	var total Money
+	for _, player := range p {
+		balance := player.Stack.Sub(player.BuyIn)
+		rounded := balance.Floor(unit)
+		player.Stack = player.BuyIn.Add(rounded)
+		remainders = append(remainders, remainder{player: player, amount: balance.Sub(rounded)})
+		total = total.Add(balance.Sub(rounded))
+	}
+	sort.SliceStable(remainders, func(i, j int) bool {
+		if c := remainders[i].amount.Cmp(remainders[j].amount); c != 0 {
+			return c > 0
+		}
+		return lessName(remainders[i].player.Name, remainders[j].player.Name)
+	})
+	// The balances add up to zero and the rounded ones are multiples of the
+	// unit, so the remainders add up to a multiple of the unit.
+	for i := 0; total.Sign() > 0; i++ {
+		player := remainders[i].player
+		player.Stack = player.Stack.Add(unit)
+		remainders[i].amount = remainders[i].amount.Sub(unit)
+		total = total.Sub(unit)
+	}
+
+	ret := make(map[string]Money)
+	for _, r := range remainders {
+		if !r.amount.IsZero() {
+			ret[r.player.Name] = r.amount.Neg()
+		}
+	}
+	return ret
+}
@@ -0,0 +1,180 @@
+package players
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// maxAmount is the largest amount accepted in a form. Anything bigger is most
+// likely a typo.
+var maxAmount = Cents(100000000)
+
+// maxDecimals is the largest number of decimals of the amounts accepted in a
+// form. It allows for currencies divided in thousandths and for fractions of
+// cents, but anything more precise is most likely a typo.
+const maxDecimals = 4
+
+// FieldErrors maps the names of the invalid fields of a form to the reason
+// why their value is invalid.
+type FieldErrors map[string]error
+
+func (e FieldErrors) Error() string {
+	var fields []string
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	var msgs []string
+	for _, field := range fields {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", field, e[field]))
+	}
+	return "invalid form fields: " + strings.Join(msgs, "; ")
+}
+
+// DecimalSeparator returns the decimal separator used in the language.
+func DecimalSeparator(tag language.Tag) string {
+	return strings.Trim(message.NewPrinter(tag).Sprintf("%.1f", 0.5), "05")
+}
+
+// GroupSeparator returns the separator used in the language to group the
+// digits of numbers by thousands.
+func GroupSeparator(tag language.Tag) string {
+	return strings.Trim(message.NewPrinter(tag).Sprintf("%d", 1000), "10")
+}
+
+// groupSeparators are the characters used to group the digits of amounts in
+// supported languages, in addition to the commas and periods.
+const groupSeparators = "'’ \u00a0\u202f"
+
+// parseAmount parses an amount in full currency units, e.g. "12.50", without
+// any loss of precision. An empty amount is zero.
+//
+// The amount may contain group separators, e.g. "1'000.50" or "1.000,50".
+// Commas and periods are ambiguous: they are considered to be the decimal
+// separator unless they appear multiple times or in combination with the
+// other one, or they aren't the decimal separator of the language and are
+// followed by exactly three digits.
+func parseAmount(s, decimalSep string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Money{}, nil
+	}
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	s = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(groupSeparators, r) {
+			return -1
+		}
+		return r
+	}, s)
+
+	// Find out which of the commas and periods is the decimal separator, if
+	// any, and get rid of the group separators.
+	sep := ""
+	lastComma, lastPeriod := strings.LastIndex(s, ","), strings.LastIndex(s, ".")
+	switch {
+	case lastComma >= 0 && lastPeriod >= 0:
+		sep = ","
+		group := "."
+		if lastPeriod > lastComma {
+			sep, group = ".", ","
+		}
+		i := strings.LastIndex(s, sep)
+		units, ok := ungroup(s[:i], group)
+		if !ok {
+			return Money{}, errorf("must be a number")
+		}
+		s = units + s[i:]
+	case lastComma >= 0 || lastPeriod >= 0:
+		sep = ","
+		if lastPeriod >= 0 {
+			sep = "."
+		}
+		if strings.Count(s, sep) > 1 || (sep != decimalSep && len(s)-strings.Index(s, sep)-1 == 3) {
+			units, ok := ungroup(s, sep)
+			if !ok {
+				return Money{}, errorf("must be a number")
+			}
+			s, sep = units, ""
+		}
+	}
+
+	units, decimals := s, ""
+	if sep != "" {
+		i := strings.LastIndex(s, sep)
+		units, decimals = s[:i], s[i+1:]
+	}
+	if (units == "" && decimals == "") || !isDigits(units) || !isDigits(decimals) {
+		return Money{}, errorf("must be a number")
+	}
+	if negative {
+		return Money{}, errorf("must not be negative")
+	}
+	// Trailing zeros don't change the amount, e.g. "1.000" in English.
+	decimals = strings.TrimRight(decimals, "0")
+	if len(decimals) > maxDecimals {
+		return Money{}, errorf("must not have more than %d decimals", maxDecimals)
+	}
+	if units == "" {
+		units = "0"
+	}
+	if decimals != "" {
+		units += "." + decimals
+	}
+	m, err := ParseMoney(units)
+	if err != nil {
+		return Money{}, errorf("must be a number")
+	}
+	if m.Cmp(maxAmount) > 0 {
+		return Money{}, errorf("is too large")
+	}
+	return m, nil
+}
+
+// parseMethods validates the payment methods submitted in a form and returns
+// them in the order of Methods, without duplicates.
+func parseMethods(values []string) ([]string, error) {
+	submitted := make(map[string]bool)
+	for _, v := range values {
+		submitted[v] = true
+	}
+	var ret []string
+	for _, m := range Methods {
+		if submitted[m] {
+			ret = append(ret, m)
+			delete(submitted, m)
+		}
+	}
+	for _, v := range values {
+		if submitted[v] {
+			return nil, errorf("unsupported payment method %q", v)
+		}
+	}
+	return ret, nil
+}
+
+// ungroup removes the group separators from the digits. It returns false if
+// the groups following the first one aren't made of exactly 3 digits.
+func ungroup(s, group string) (string, bool) {
+	groups := strings.Split(s, group)
+	for _, g := range groups[1:] {
+		if len(g) != 3 {
+			return "", false
+		}
+	}
+	return strings.Join(groups, ""), true
+}
+
+// isDigits returns whether s only consists of ASCII digits.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
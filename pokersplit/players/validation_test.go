@@ -0,0 +1,115 @@
+package players
+
+import (
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{input: "", want: Money{}},
+		{input: "  ", want: Money{}},
+		{input: "0", want: Cents(0)},
+		{input: "12", want: Cents(1200)},
+		{input: " 12.5 ", want: Cents(1250)},
+		{input: "12.05", want: Cents(1205)},
+		{input: ".5", want: Cents(50)},
+		{input: "12.", want: Cents(1200)},
+		{input: "0001", want: Cents(100)},
+		{input: "1.000", want: Cents(100)},
+		{input: "1000000", want: Cents(100000000)},
+		{input: "12,50", want: Cents(1250)},
+		{input: "1,000", want: Cents(100000)},
+		{input: "1,000,000", want: Cents(100000000)},
+		{input: "1,000.50", want: Cents(100050)},
+		{input: "1'000.50", want: Cents(100050)},
+		{input: "1’000.50", want: Cents(100050)},
+		{input: "1 000.50", want: Cents(100050)},
+		{input: ".", wantErr: true},
+		{input: "abc", wantErr: true},
+		{input: "1e3", wantErr: true},
+		{input: "1.2.3", wantErr: true},
+		{input: "1.2,3.4", wantErr: true},
+		{input: "-5", wantErr: true},
+		{input: "1.005", want: mustParseMoney("1.005")},
+		{input: "1.00050", want: mustParseMoney("1.0005")},
+		{input: "1.00005", wantErr: true},
+		{input: "1000000.01", wantErr: true},
+		{input: "99999999999999999999999", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			got, err := parseAmount(c.input, ".")
+			if err != nil && !c.wantErr {
+				t.Fatalf("parseAmount(%q) returned an error: %v", c.input, err)
+			}
+			if err == nil && c.wantErr {
+				t.Fatalf("parseAmount(%q) = %v, but an error was expected", c.input, got)
+			}
+			if !got.Equal(c.want) {
+				t.Errorf("parseAmount(%q) = %v, want %v", c.input, got, c.want)
+			}
+		})
+	}
+}
+
+// TestParseAmountLanguages tests that amounts are parsed according to the
+// conventions of the language.
+func TestParseAmountLanguages(t *testing.T) {
+	cases := []struct {
+		lang    string
+		input   string
+		want    Money
+		wantErr bool
+	}{
+		{lang: "en", input: "1,000", want: Cents(100000)},
+		{lang: "en", input: "1.50", want: Cents(150)},
+		{lang: "de", input: "1.000", want: Cents(100000)},
+		{lang: "de", input: "1.000,50", want: Cents(100050)},
+		{lang: "de", input: "12,50", want: Cents(1250)},
+		{lang: "de", input: "1,000", want: Cents(100)},
+		{lang: "de", input: "1,005", want: mustParseMoney("1.005")},
+		{lang: "de", input: "1,00005", wantErr: true},
+		{lang: "de-CH", input: "12,50", want: Cents(1250)},
+		{lang: "de-CH", input: "1'000.50", want: Cents(100050)},
+		{lang: "de-CH", input: "1’000", want: Cents(100000)},
+		{lang: "fr", input: "1\u202f000,50", want: Cents(100050)},
+		{lang: "fr", input: "1 000,5", want: Cents(100050)},
+	}
+	for _, c := range cases {
+		t.Run(c.lang+"_"+c.input, func(t *testing.T) {
+			sep := DecimalSeparator(language.MustParse(c.lang))
+			got, err := parseAmount(c.input, sep)
+			if err != nil && !c.wantErr {
+				t.Fatalf("parseAmount(%q, %q) returned an error: %v", c.input, sep, err)
+			}
+			if err == nil && c.wantErr {
+				t.Fatalf("parseAmount(%q, %q) = %v, but an error was expected", c.input, sep, got)
+			}
+			if !got.Equal(c.want) {
+				t.Errorf("parseAmount(%q, %q) = %v, want %v", c.input, sep, got, c.want)
+			}
+		})
+	}
+}
+
+func TestDecimalSeparator(t *testing.T) {
+	for lang, want := range map[string]string{"en": ".", "fr": ",", "de": ",", "de-CH": "."} {
+		if got := DecimalSeparator(language.MustParse(lang)); got != want {
+			t.Errorf("DecimalSeparator(%s) = %q, want %q", lang, got, want)
+		}
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	errs := FieldErrors{"stack1": errorf("must be a number"), "buyin0": errorf("must not be negative")}
+	want := "invalid form fields: buyin0: must not be negative; stack1: must be a number"
+	if got := errs.Error(); got != want {
+		t.Errorf("FieldErrors.Error() = %q, want %q", got, want)
+	}
+}
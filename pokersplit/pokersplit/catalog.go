@@ -0,0 +1,236 @@
+package pokersplit
+
+import (
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// translation holds the translations of a message, which is its English
+// version.
+type translation struct {
+	msg string
+	fr  string
+	de  string
+}
+
+// translations lists all the messages of the user interface, including the
+// error messages of the players package.
+var translations = []translation{
+	// Templates.
+	{"Error:", "Erreur :", "Fehler:"},
+	{"Language", "Langue", "Sprache"},
+	{"PokerSplit is made for casual cash games between friends.", "PokerSplit est fait pour les parties de cash game entre amis.", "PokerSplit ist für gemütliche Cash Games unter Freunden gemacht."},
+	{"It lets you enjoy your game without worrying how to split the money among the winners at the end of the game.", "Profitez de votre partie sans vous soucier de comment répartir l'argent entre les gagnants à la fin.", "Geniesse dein Spiel, ohne dich darum zu kümmern, wie das Geld am Ende unter den Gewinnern aufgeteilt wird."},
+	{"It's super simple!", "C'est super simple !", "Es ist ganz einfach!"},
+	{"Register the players' name and buy-in.", "Enregistrez le nom et le buy-in des joueurs.", "Erfasse die Namen und Buy-ins der Spieler."},
+	{"Update the buy-ins when players rebuy.", "Mettez à jour les buy-ins lorsque des joueurs recavent.", "Aktualisiere die Buy-ins, wenn Spieler nachkaufen."},
+	{"At the end of the game, record each player's stack.", "À la fin de la partie, enregistrez le tapis de chaque joueur.", "Erfasse am Ende des Spiels den Stack jedes Spielers."},
+	{"PokerSplit will display who owes how much to whom once the sum of all buy-ins matches the sum of all stacks.", "PokerSplit affichera qui doit combien à qui dès que la somme des buy-ins correspond à la somme des tapis.", "PokerSplit zeigt an, wer wem wie viel schuldet, sobald die Summe der Buy-ins der Summe der Stacks entspricht."},
+	{"Source Code", "Code source", "Quellcode"},
+	{"Player", "Joueur", "Spieler"},
+	{"Buy-In", "Buy-in", "Buy-in"},
+	{"Stack", "Tapis", "Stack"},
+	{"Total", "Total", "Total"},
+	{"Payment Methods", "Moyens de paiement", "Zahlungsmittel"},
+	{"Cash", "Espèces", "Bargeld"},
+	{"Mobile payment", "Paiement mobile", "Mobile Zahlung"},
+	{"Optionally, tick the payment methods each player accepts. Players who don't tick any accept all of them.", "Si vous le souhaitez, cochez les moyens de paiement acceptés par chaque joueur. Les joueurs qui n'en cochent aucun les acceptent tous.", "Kreuze bei Bedarf die Zahlungsmittel an, die jeder Spieler akzeptiert. Spieler ohne Angabe akzeptieren alle."},
+	{"Settlement Constraints", "Contraintes de règlement", "Einschränkungen der Abrechnung"},
+	{"%s and %s must not settle debts with each other.", "%s et %s ne doivent pas régler de dettes entre eux.", "%s und %s dürfen keine Schulden miteinander begleichen."},
+	{"%s and %s prefer to settle debts with each other.", "%s et %s préfèrent régler leurs dettes entre eux.", "%s und %s begleichen ihre Schulden lieber miteinander."},
+	{"Constraint", "Contrainte", "Einschränkung"},
+	{"must not settle debts with", "ne doit pas régler de dettes avec", "darf keine Schulden begleichen mit"},
+	{"prefers to settle debts with", "préfère régler ses dettes avec", "begleicht Schulden lieber mit"},
+	{"Add", "Ajouter", "Hinzufügen"},
+	{"Avoids", "Évite", "Meidet"},
+	{"Prefers", "Préfère", "Bevorzugt"},
+	{"IBAN", "IBAN", "IBAN"},
+	{"Postal Code and Town", "NPA et localité", "PLZ und Ort"},
+	{"Optionally, players who want to be paid by bank transfer enter their IBAN, and their postal code and town for Swiss IBANs, to get a QR code for each debt.", "Facultativement, les joueurs qui souhaitent être payés par virement saisissent leur IBAN, ainsi que leur NPA et localité pour les IBAN suisses, afin d'obtenir un code QR pour chaque dette.", "Optional geben Spieler, die per Überweisung bezahlt werden möchten, ihre IBAN ein, bei Schweizer IBANs auch PLZ und Ort, um für jede Schuld einen QR-Code zu erhalten."},
+	{"QR code to pay %s", "Code QR pour payer %s", "QR-Code, um %s zu bezahlen"},
+	{"No QR code: %s", "Pas de code QR : %s", "Kein QR-Code: %s"},
+	{"Paid", "Payé", "Bezahlt"},
+	{"Mark the debts as paid once they are, to keep track of the outstanding ones.", "Marquez les dettes comme payées une fois réglées, pour suivre celles en suspens.", "Markiere die Schulden als bezahlt, sobald sie beglichen sind, um den Überblick über die offenen zu behalten."},
+	{"paid", "payé", "bezahlt"},
+	{"Mark as paid", "Marquer comme payé", "Als bezahlt markieren"},
+	{"Mark as unpaid", "Marquer comme impayé", "Als unbezahlt markieren"},
+	{"Show the exact amounts to mark debts as paid.", "Affichez les montants exacts pour marquer des dettes comme payées.", "Zeige die genauen Beträge an, um Schulden als bezahlt zu markieren."},
+	{"Outstanding debts:", "Dettes en suspens :", "Offene Schulden:"},
+	{"%s still owes %s", "%s doit encore %s", "%s schuldet noch %s"},
+	{"All the debts are paid.", "Toutes les dettes sont payées.", "Alle Schulden sind bezahlt."},
+	{"Settle Several Games", "Régler plusieurs parties", "Mehrere Spiele abrechnen"},
+	{"Settle several games at once", "Régler plusieurs parties à la fois", "Mehrere Spiele auf einmal abrechnen"},
+	{"Paste the URLs of the games played since the last settlement, one per line. The debts which weren't marked as paid are netted and settled at once.", "Collez les URL des parties jouées depuis le dernier règlement, une par ligne. Les dettes qui n'ont pas été marquées comme payées sont compensées et réglées en une fois.", "Füge die URLs der seit der letzten Abrechnung gespielten Spiele ein, eine pro Zeile. Die nicht als bezahlt markierten Schulden werden verrechnet und auf einmal beglichen."},
+	{"Games", "Parties", "Spiele"},
+	{"Settle", "Régler", "Abrechnen"},
+	{"New game", "Nouvelle partie", "Neues Spiel"},
+	{"Balance", "Solde", "Saldo"},
+	{"Download report (PDF)", "Télécharger le rapport (PDF)", "Bericht herunterladen (PDF)"},
+	{"PokerSplit Game Report", "Rapport de partie PokerSplit", "PokerSplit-Spielbericht"},
+	{"Generated on %s", "Généré le %s", "Erstellt am %s"},
+	{"Net", "Net", "Netto"},
+	{"Transfers", "Virements", "Überweisungen"},
+	{"Share of Pot", "Part du pot", "Anteil am Pot"},
+	{"Biggest winner:", "Plus gros gain :", "Grösster Gewinner:"},
+	{"Biggest loser:", "Plus grosse perte :", "Grösster Verlierer:"},
+	{"More rows", "Plus de lignes", "Mehr Zeilen"},
+	{"Add several players at once, one name per line or separated by commas:", "Ajoutez plusieurs joueurs à la fois, un nom par ligne ou séparés par des virgules :", "Füge mehrere Spieler auf einmal hinzu, ein Name pro Zeile oder durch Kommas getrennt:"},
+	{"Copy summary", "Copier le résumé", "Zusammenfassung kopieren"},
+	{"Copy", "Copier", "Kopieren"},
+	{"Markdown", "Markdown", "Markdown"},
+	{"Markdown with emojis", "Markdown avec emojis", "Markdown mit Emojis"},
+	{"Plain text with emojis", "Texte brut avec emojis", "Klartext mit Emojis"},
+	{"PokerSplit result", "Résultat PokerSplit", "PokerSplit-Ergebnis"},
+	{"Net results", "Résultats nets", "Nettoergebnisse"},
+	{"Who owes whom", "Qui doit à qui", "Wer schuldet wem"},
+	{"Nobody owes anything.", "Personne ne doit rien.", "Niemand schuldet etwas."},
+	{"%s owes %s to %s", "%s doit %s à %s", "%[1]s schuldet %[3]s %[2]s"},
+	{"(paid)", "(payé)", "(bezahlt)"},
+	{"Share this game", "Partager cette partie", "Dieses Spiel teilen"},
+	{"Scan this QR code to open the game on another device.", "Scannez ce code QR pour ouvrir la partie sur un autre appareil.", "Scanne diesen QR-Code, um das Spiel auf einem anderen Gerät zu öffnen."},
+	{"The link to this game is too long to be encoded in a QR code. Save the game to get a shorter link, or copy the address of this page instead.", "Le lien de cette partie est trop long pour être encodé dans un code QR. Enregistrez la partie pour obtenir un lien plus court, ou copiez plutôt l'adresse de cette page.", "Der Link zu diesem Spiel ist zu lang für einen QR-Code. Speichere das Spiel, um einen kürzeren Link zu erhalten, oder kopiere stattdessen die Adresse dieser Seite."},
+	{"QR code of this game", "Code QR de cette partie", "QR-Code dieses Spiels"},
+	{"no common payment method", "aucun moyen de paiement commun", "kein gemeinsames Zahlungsmittel"},
+	{"Your name", "Votre nom", "Dein Name"},
+	{"Save", "Enregistrer", "Speichern"},
+	{"History", "Historique", "Verlauf"},
+	{"New name", "Nouveau nom", "Neuer Name"},
+	{"Rename", "Renommer", "Umbenennen"},
+	{"Remove", "Retirer", "Entfernen"},
+	{"Remove this player from the game?", "Retirer ce joueur de la partie ?", "Diesen Spieler aus dem Spiel entfernen?"},
+	{"Undo last change", "Annuler la dernière modification", "Letzte Änderung rückgängig machen"},
+	{"Redo", "Rétablir", "Wiederherstellen"},
+	{"%s owes", "%s doit", "%s schuldet"},
+	{"%s to %s", "%s à %s", "%s an %s"},
+	{"Round the transfers to", "Arrondir les virements à", "Überweisungen runden auf"},
+	{"Exact amounts", "Montants exacts", "Genaue Beträge"},
+	{"Apply", "Appliquer", "Anwenden"},
+	{"To round the transfers, the following balances were adjusted:", "Pour arrondir les virements, les soldes suivants ont été ajustés :", "Um die Überweisungen zu runden, wurden folgende Salden angepasst:"},
+	{"%s gets %s more", "%s reçoit %s de plus", "%s erhält %s mehr"},
+	{"%s gets %s less", "%s reçoit %s de moins", "%s erhält %s weniger"},
+	{"Conflict:", "Conflit :", "Konflikt:"},
+	{"someone else saved this game while you were editing it.", "quelqu'un d'autre a enregistré cette partie pendant que vous la modifiiez.", "jemand anderes hat dieses Spiel gespeichert, während du es bearbeitet hast."},
+	{"Your changes were not saved. The highlighted values differ between their version and yours.", "Vos modifications n'ont pas été enregistrées. Les valeurs en surbrillance diffèrent entre leur version et la vôtre.", "Deine Änderungen wurden nicht gespeichert. Die hervorgehobenen Werte unterscheiden sich zwischen ihrer und deiner Version."},
+	{"Before", "Avant", "Vorher"},
+	{"Theirs", "La leur", "Ihre"},
+	{"Yours", "La vôtre", "Deine"},
+	{"Keep their version", "Garder leur version", "Ihre Version behalten"},
+	{"Overwrite with your version", "Écraser avec votre version", "Mit deiner Version überschreiben"},
+	{"Revisions", "Révisions", "Revisionen"},
+	{"The history is informative only: the editors aren't authenticated, and anyone with the link to the game can modify it.", "L'historique est donné à titre indicatif : les auteurs ne sont pas authentifiés, et toute personne ayant le lien de la partie peut le modifier.", "Der Verlauf dient nur zur Information: Die Bearbeiter werden nicht authentifiziert, und alle mit dem Link zum Spiel können ihn ändern."},
+	{"Only the last %d revisions are kept.", "Seules les %d dernières révisions sont conservées.", "Nur die letzten %d Revisionen werden aufbewahrt."},
+	{"Revision", "Révision", "Revision"},
+	{"Time (UTC)", "Heure (UTC)", "Zeit (UTC)"},
+	{"Editor", "Auteur", "Bearbeiter"},
+	{"Changes", "Modifications", "Änderungen"},
+	{"%d (undo of revision %d)", "%d (annulation de la révision %d)", "%d (Rückgängigmachung von Revision %d)"},
+	{"current", "actuelle", "aktuell"},
+	{"Restore", "Restaurer", "Wiederherstellen"},
+	{"Back to the game", "Retour à la partie", "Zurück zum Spiel"},
+	{"Export:", "Exporter :", "Exportieren:"},
+	{"Field", "Champ", "Feld"},
+	{"Name", "Nom", "Name"},
+	{"Old Value", "Ancienne valeur", "Alter Wert"},
+	{"New Value", "Nouvelle valeur", "Neuer Wert"},
+	{"No changes recorded.", "Aucune modification enregistrée.", "Keine Änderungen erfasst."},
+	{"Presets", "Préréglages", "Vorlagen"},
+	{"A preset starts new games with the same players, who already bought in for the standard amount.", "Un préréglage démarre de nouvelles parties avec les mêmes joueurs, qui ont déjà payé le buy-in standard.", "Eine Vorlage startet neue Spiele mit denselben Spielern, die bereits den Standard-Buy-in bezahlt haben."},
+	{"Bookmark this link to start a new game from the preset:", "Ajoutez ce lien à vos favoris pour démarrer une nouvelle partie avec ce préréglage :", "Setze ein Lesezeichen auf diesen Link, um ein neues Spiel mit dieser Vorlage zu starten:"},
+	{"Players, one name per line or separated by commas:", "Joueurs, un nom par ligne ou séparés par des virgules :", "Spieler, ein Name pro Zeile oder durch Kommas getrennt:"},
+	{"Standard buy-in", "Buy-in standard", "Standard-Buy-in"},
+	{"Standard buy-in:", "Buy-in standard :", "Standard-Buy-in:"},
+	{"Currency", "Monnaie", "Währung"},
+	{"Chip values, separated by spaces", "Valeurs des jetons, séparées par des espaces", "Chipwerte, durch Leerzeichen getrennt"},
+	{"Chips:", "Jetons :", "Chips:"},
+	{"Save preset", "Enregistrer le préréglage", "Vorlage speichern"},
+	{"Start from a preset", "Démarrer avec un préréglage", "Mit einer Vorlage starten"},
+	{"Save as preset", "Enregistrer comme préréglage", "Als Vorlage speichern"},
+	{"Rebuy", "Recave", "Nachkauf"},
+	{"{debtor} owes {amount} to {creditor}", "{debtor} doit {amount} à {creditor}", "{debtor} schuldet {creditor} {amount}"},
+	{"{players} can't settle their debts without paying players they avoid", "{players} ne peuvent pas régler leurs dettes sans payer des joueurs qu'ils évitent", "{players} können ihre Schulden nicht begleichen, ohne Spieler zu bezahlen, die sie meiden"},
+	{"You are offline.", "Vous êtes hors ligne.", "Du bist offline."},
+	{"The game will be saved once you are back online. Meanwhile, the debts were calculated on this device:", "La partie sera enregistrée dès que vous serez de nouveau en ligne. En attendant, les dettes ont été calculées sur cet appareil :", "Das Spiel wird gespeichert, sobald du wieder online bist. Bis dahin wurden die Schulden auf diesem Gerät berechnet:"},
+
+	// Errors of the pokersplit package.
+	{"unsupported HTTP method: %s", "méthode HTTP non prise en charge : %s", "nicht unterstützte HTTP-Methode: %s"},
+	{"failed to update the debt: %v", "impossible de mettre à jour la dette : %v", "Schuld konnte nicht aktualisiert werden: %v"},
+	{"failed to load preset %q: %v", "impossible de charger le préréglage %q : %v", "Vorlage %q konnte nicht geladen werden: %v"},
+	{"the standard buy-in isn't set", "le buy-in standard n'est pas défini", "der Standard-Buy-in ist nicht festgelegt"},
+	{"failed to rebuy: %v", "impossible de recaver : %v", "Nachkauf fehlgeschlagen: %v"},
+	{"failed to encode preset: %v", "impossible d'encoder le préréglage : %v", "Vorlage konnte nicht kodiert werden: %v"},
+	{"failed to load game %q: %v", "impossible de charger la partie %q : %v", "Spiel %q konnte nicht geladen werden: %v"},
+	{"failed to calculate the debts of game %q: %v", "impossible de calculer les dettes de la partie %q : %v", "Schulden von Spiel %q konnten nicht berechnet werden: %v"},
+	{"failed to generate the QR code: %v", "impossible de générer le code QR : %v", "QR-Code konnte nicht erstellt werden: %v"},
+	{"the link to the game is too long to be encoded in a QR code", "le lien de la partie est trop long pour être encodé dans un code QR", "der Link zum Spiel ist zu lang für einen QR-Code"},
+	{"game %q not found, it may have been forgotten since it was shared", "partie %q introuvable, elle a peut-être été oubliée depuis qu'elle a été partagée", "Spiel %q nicht gefunden, es wurde seit dem Teilen möglicherweise vergessen"},
+	{"failed to decode players: %v", "impossible de décoder les joueurs : %v", "Spieler konnten nicht dekodiert werden: %v"},
+	{"failed to encode players: %v", "impossible d'encoder les joueurs : %v", "Spieler konnten nicht kodiert werden: %v"},
+	{"failed to calculate debts: %v", "impossible de calculer les dettes : %v", "Schulden konnten nicht berechnet werden: %v"},
+	{"failed to parse form: %v", "impossible de lire le formulaire : %v", "Formular konnte nicht gelesen werden: %v"},
+	{"failed to parse players from form: %v", "impossible de lire les joueurs du formulaire : %v", "Spieler konnten nicht aus dem Formular gelesen werden: %v"},
+	{"missing or invalid revision: %v", "révision manquante ou invalide : %v", "fehlende oder ungültige Revision: %v"},
+	{"revision %d doesn't match the game's revision %d", "la révision %d ne correspond pas à la révision %d de la partie", "Revision %d entspricht nicht der Revision %d des Spiels"},
+	{"some values are invalid, please correct them", "certaines valeurs sont invalides, veuillez les corriger", "einige Werte sind ungültig, bitte korrigieren"},
+	{"missing or invalid revision to restore: %v", "révision à restaurer manquante ou invalide : %v", "fehlende oder ungültige wiederherzustellende Revision: %v"},
+	{"failed to rename player: %v", "impossible de renommer le joueur : %v", "Spieler konnte nicht umbenannt werden: %v"},
+	{"failed to remove player: %v", "impossible de retirer le joueur : %v", "Spieler konnte nicht entfernt werden: %v"},
+	{"failed to add the constraint: %v", "impossible d'ajouter la contrainte : %v", "Einschränkung konnte nicht hinzugefügt werden: %v"},
+	{"failed to remove the constraint: %v", "impossible de retirer la contrainte : %v", "Einschränkung konnte nicht entfernt werden: %v"},
+	{"unsupported action: %q", "action non prise en charge : %q", "nicht unterstützte Aktion: %q"},
+	{"unsupported sort order: %q", "ordre de tri non pris en charge : %q", "nicht unterstützte Sortierung: %q"},
+	{"invalid rounding unit: %v", "unité d'arrondi invalide : %v", "ungültige Rundungseinheit: %v"},
+	{"unsupported format: %q", "format non pris en charge : %q", "nicht unterstütztes Format: %q"},
+
+	// Errors of the players package.
+	{"failed to generate game ID: %v", "impossible de générer l'identifiant de la partie : %v", "Spiel-ID konnte nicht generiert werden: %v"},
+	{"failed to decode JSON: %v", "impossible de décoder le JSON : %v", "JSON konnte nicht dekodiert werden: %v"},
+	{"gzip decompression failed: %v", "la décompression gzip a échoué : %v", "gzip-Dekomprimierung fehlgeschlagen: %v"},
+	{"invalid amount %q", "montant invalide %q", "ungültiger Betrag %q"},
+	{"invalid amount %q: %v", "montant invalide %q : %v", "ungültiger Betrag %q: %v"},
+	{"player %q not found", "joueur %q introuvable", "Spieler %q nicht gefunden"},
+	{"unknown field %q", "champ inconnu %q", "unbekanntes Feld %q"},
+	{"revision %d doesn't exist", "la révision %d n'existe pas", "Revision %d existiert nicht"},
+	{"revision %d is missing from the history", "la révision %d manque dans l'historique", "Revision %d fehlt im Verlauf"},
+	{"failed to revert revision %d: %v", "impossible d'annuler la révision %d : %v", "Revision %d konnte nicht zurückgesetzt werden: %v"},
+	{"revision %d can't be undone", "la révision %d ne peut pas être annulée", "Revision %d kann nicht rückgängig gemacht werden"},
+	{"failed to undo revision %d: %v", "impossible d'annuler la révision %d : %v", "Revision %d konnte nicht rückgängig gemacht werden: %v"},
+	{"there is nothing to redo", "il n'y a rien à rétablir", "es gibt nichts wiederherzustellen"},
+	{"failed to redo revision %d: %v", "impossible de rétablir la révision %d : %v", "Revision %d konnte nicht wiederhergestellt werden: %v"},
+	{"failed to restore revision %d: %v", "impossible de restaurer la révision %d : %v", "Revision %d konnte nicht wiederhergestellt werden: %v"},
+	{"duplicate player with name %q", "il y a déjà un joueur nommé %q", "es gibt bereits einen Spieler namens %q"},
+	{"duplicate player with name %q, which is the same as %q", "le nom %q est identique à celui du joueur %q", "der Name %q entspricht dem des Spielers %q"},
+	{"the new name of %q is empty", "le nouveau nom de %q est vide", "der neue Name von %q ist leer"},
+	{"the total of the buy-ins doesn't match the total of the stacks", "le total des buy-ins ne correspond pas au total des tapis", "die Summe der Buy-ins entspricht nicht der Summe der Stacks"},
+	{"%q can't settle debts with themselves", "%q ne peut pas régler de dettes avec lui-même", "%q kann keine Schulden mit sich selbst begleichen"},
+	{"%q can't both avoid and prefer settling debts with %q", "%q ne peut pas à la fois éviter et préférer régler ses dettes avec %q", "%q kann Schulden mit %q nicht zugleich meiden und bevorzugen"},
+	{"invalid paid debt %q", "dette payée invalide %q", "ungültige bezahlte Schuld %q"},
+	{"%s doesn't owe anything to %s", "%s ne doit rien à %s", "%s schuldet %s nichts"},
+	{"failed to calculate the debts of game %d: %v", "impossible de calculer les dettes de la partie %d : %v", "Schulden von Spiel %d konnten nicht berechnet werden: %v"},
+	{"%q didn't provide their IBAN", "%q n'a pas fourni son IBAN", "%q hat keine IBAN angegeben"},
+	{"the IBAN of %q %v", "l'IBAN de %q %v", "die IBAN von %q %v"},
+	{"%s can't be paid using a payment request", "%s ne peut pas être payé avec une demande de paiement", "%s kann nicht mit einer Zahlungsanforderung bezahlt werden"},
+	{"the IBAN of %q is a QR-IBAN, which requires a reference", "l'IBAN de %q est un QR-IBAN, qui nécessite une référence", "die IBAN von %q ist eine QR-IBAN, die eine Referenz erfordert"},
+	{"the postal code and town of %q are needed for a Swiss QR-bill", "le NPA et la localité de %q sont nécessaires pour une QR-facture suisse", "PLZ und Ort von %q werden für eine Schweizer QR-Rechnung benötigt"},
+	{"the IBAN of %q only accepts payment requests in EUR, not %s", "l'IBAN de %q n'accepte que les demandes de paiement en EUR, pas %s", "die IBAN von %q akzeptiert nur Zahlungsanforderungen in EUR, nicht %s"},
+	{"the IBAN of %q only accepts payment requests in CHF or EUR, not %s", "l'IBAN de %q n'accepte que les demandes de paiement en CHF ou EUR, pas %s", "die IBAN von %q akzeptiert nur Zahlungsanforderungen in CHF oder EUR, nicht %s"},
+	{"is not a valid IBAN", "n'est pas un IBAN valide", "ist keine gültige IBAN"},
+	{"%s can't settle their debts without paying players they avoid", "%s ne peuvent pas régler leurs dettes sans payer des joueurs qu'ils évitent", "%s können ihre Schulden nicht begleichen, ohne Spieler zu bezahlen, die sie meiden"},
+	{"the rounding unit must not be negative", "l'unité d'arrondi ne doit pas être négative", "die Rundungseinheit darf nicht negativ sein"},
+	{"unsupported payment method %q", "moyen de paiement non pris en charge : %q", "nicht unterstütztes Zahlungsmittel: %q"},
+	{"must be a number", "doit être un nombre", "muss eine Zahl sein"},
+	{"must not be negative", "ne doit pas être négatif", "darf nicht negativ sein"},
+	{"must not have more than %d decimals", "ne doit pas avoir plus de %d décimales", "darf nicht mehr als %d Nachkommastellen haben"},
+	{"is too large", "est trop grand", "ist zu gross"},
+	{"must be a code of 3 letters, e.g. CHF", "doit être un code de 3 lettres, p. ex. CHF", "muss ein Code aus 3 Buchstaben sein, z. B. CHF"},
+	{"the amount of a rebuy must be positive", "le montant d'une recave doit être positif", "der Betrag eines Nachkaufs muss positiv sein"},
+	{"the value of a chip must be positive", "la valeur d'un jeton doit être positive", "der Wert eines Chips muss positiv sein"},
+	{"preset %q is empty", "le préréglage %q est vide", "die Vorlage %q ist leer"},
+	{"preset %q is invalid: %v", "le préréglage %q est invalide : %v", "die Vorlage %q ist ungültig: %v"},
+}
+
+func init() {
+	for _, t := range translations {
+		message.SetString(language.French, t.msg, t.fr)
+		message.SetString(language.German, t.msg, t.de)
+	}
+}
@@ -0,0 +1,40 @@
+package pokersplit
+
+import (
+	"regexp"
+	"testing"
+)
+
+// TestTranslationsComplete tests that all the messages of the templates are
+// translated to all languages.
+func TestTranslationsComplete(t *testing.T) {
+	translated := make(map[string]bool)
+	for _, tr := range translations {
+		if tr.fr == "" || tr.de == "" {
+			t.Errorf("message %q isn't translated to all languages", tr.msg)
+		}
+		if translated[tr.msg] {
+			t.Errorf("message %q is translated more than once", tr.msg)
+		}
+		translated[tr.msg] = true
+	}
+
+	msgRe := regexp.MustCompile(`{{T "((?:[^"\\]|\\.)*)"`)
+	for name, src := range map[string]string{"index": index, "merge": merge, "history": history, "net": netPage, "new": newPage} {
+		for _, m := range msgRe.FindAllStringSubmatch(src, -1) {
+			if !translated[m[1]] {
+				t.Errorf("message %q of template %q isn't translated", m[1], name)
+			}
+		}
+	}
+	for _, label := range fieldLabels {
+		if !translated[label] {
+			t.Errorf("field label %q isn't translated", label)
+		}
+	}
+	for _, label := range methodLabels {
+		if !translated[label] {
+			t.Errorf("payment method label %q isn't translated", label)
+		}
+	}
+}
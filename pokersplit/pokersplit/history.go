@@ -0,0 +1,148 @@
+package pokersplit
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+)
+
+//go:embed history.tmpl
+var history string
+
+var historyTmpl = template.Must(template.New("history").Funcs(funcs).Funcs(template.FuncMap{
+	// FieldLabel returns the label of the field, as displayed in the form.
+	"FieldLabel": func(field string) string {
+		return fieldLabels[field]
+	},
+}).Parse(history))
+
+// fieldLabels maps the fields of a players.Change to their label.
+var fieldLabels = map[string]string{
+	players.FieldPlayer:        "Player",
+	players.FieldName:          "Name",
+	players.FieldBuyIn:         "Buy-In",
+	players.FieldStack:         "Stack",
+	players.FieldMethods:       "Payment Methods",
+	players.FieldAvoids:        "Avoids",
+	players.FieldPrefers:       "Prefers",
+	players.FieldIBAN:          "IBAN",
+	players.FieldAddress:       "Postal Code and Town",
+	players.FieldPaid:          "Paid",
+	players.FieldStandardBuyIn: "Standard buy-in",
+}
+
+// record is a single change in the history of a game, as exported.
+type record struct {
+	Revision int       `json:"revision"`
+	Time     time.Time `json:"time"`
+	Editor   string    `json:"editor"`
+	Player   string    `json:"player"`
+	Field    string    `json:"field"`
+	Old      string    `json:"old"`
+	New      string    `json:"new"`
+}
+
+// records flattens the history of a game, newest change first.
+func records(g *players.Game) []record {
+	var ret []record
+	for i := len(g.History) - 1; i >= 0; i-- {
+		e := g.History[i]
+		for _, c := range e.Changes {
+			ret = append(ret, record{
+				Revision: e.Revision,
+				Time:     e.Time,
+				Editor:   e.Editor,
+				Player:   c.Player,
+				Field:    c.Field,
+				Old:      c.Old,
+				New:      c.New,
+			})
+		}
+	}
+	return ret
+}
+
+type historyTmplData struct {
+	// Data is the encoded game, as found in the URL.
+	Data string
+	// Revision is the current revision of the game.
+	Revision int
+	// Edits are the edits made to the game, newest first.
+	Edits   []players.Edit
+	Records []record
+	// MaxHistory is the number of edits kept in the history.
+	MaxHistory int
+	Error      error
+}
+
+// ServeHistory displays the changes made to the game encoded in the URL path,
+// after the "/history/" prefix. The "format" URL parameter can be set to "csv"
+// or "json" to export them.
+func ServeHistory(w http.ResponseWriter, r *http.Request) {
+	l := negotiate(w, r)
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		l.execute(w, historyTmpl, historyTmplData{Error: l.errorf("unsupported HTTP method: %s", r.Method)})
+		return
+	}
+	data := strings.TrimPrefix(r.URL.Path, "/history/")
+	g, err := players.GameFromBase64(data)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		l.execute(w, historyTmpl, historyTmplData{Error: l.errorf("failed to decode players: %v", err)})
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "":
+		var edits []players.Edit
+		for i := len(g.History) - 1; i >= 0; i-- {
+			edits = append(edits, g.History[i])
+		}
+		l.execute(w, historyTmpl, historyTmplData{
+			Data:       data,
+			Revision:   g.Revision,
+			Edits:      edits,
+			Records:    records(g),
+			MaxHistory: players.MaxHistory,
+		})
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="history.csv"`)
+		writeCSV(w, records(g))
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="history.json"`)
+		json.NewEncoder(w).Encode(records(g))
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		l.execute(w, historyTmpl, historyTmplData{Data: data, Error: l.errorf("unsupported format: %q", format)})
+	}
+}
+
+// writeCSV writes the records in CSV, preceded by a header line.
+func writeCSV(w io.Writer, records []record) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"revision", "time", "editor", "player", "field", "old", "new"})
+	for _, r := range records {
+		cw.Write([]string{
+			strconv.Itoa(r.Revision),
+			r.Time.Format(time.RFC3339),
+			r.Editor,
+			r.Player,
+			r.Field,
+			r.Old,
+			r.New,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
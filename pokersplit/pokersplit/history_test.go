@@ -0,0 +1,59 @@
+package pokersplit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRecordsAndWriteCSV(t *testing.T) {
+	t1 := time.Date(2021, 6, 1, 20, 0, 0, 0, time.UTC)
+	t2 := time.Date(2021, 6, 1, 23, 30, 0, 0, time.UTC)
+	g := &players.Game{
+		History: []players.Edit{
+			{
+				Revision: 1,
+				Time:     t1,
+				Editor:   "alice",
+				Changes: []players.Change{
+					{Player: "alice", Field: players.FieldPlayer, New: "alice"},
+					{Player: "alice", Field: players.FieldBuyIn, Old: "0.00", New: "20.00"},
+				},
+			},
+			{
+				Revision: 2,
+				Time:     t2,
+				Editor:   "192.0.2.1",
+				Changes: []players.Change{
+					{Player: "alice", Field: players.FieldStack, Old: "0.00", New: "35.50"},
+				},
+			},
+		},
+	}
+
+	want := []record{
+		{Revision: 2, Time: t2, Editor: "192.0.2.1", Player: "alice", Field: "stack", Old: "0.00", New: "35.50"},
+		{Revision: 1, Time: t1, Editor: "alice", Player: "alice", Field: "player", New: "alice"},
+		{Revision: 1, Time: t1, Editor: "alice", Player: "alice", Field: "buyin", Old: "0.00", New: "20.00"},
+	}
+	got := records(g)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("records() mismatch (-want +got):\n%s", diff)
+	}
+
+	wantCSV := `revision,time,editor,player,field,old,new
+2,2021-06-01T23:30:00Z,192.0.2.1,alice,stack,0.00,35.50
+1,2021-06-01T20:00:00Z,alice,alice,player,,alice
+1,2021-06-01T20:00:00Z,alice,alice,buyin,0.00,20.00
+`
+	var buf bytes.Buffer
+	if err := writeCSV(&buf, got); err != nil {
+		t.Fatalf("writeCSV() returned an error: %v", err)
+	}
+	if diff := cmp.Diff(wantCSV, buf.String()); diff != "" {
+		t.Errorf("writeCSV() mismatch (-want +got):\n%s", diff)
+	}
+}
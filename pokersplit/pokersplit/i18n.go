@@ -0,0 +1,146 @@
+package pokersplit
+
+import (
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// languages are the languages the user interface is translated to. The
+// first one is used when none of them suits the user.
+var languages = []language.Tag{language.English, language.French, language.German}
+
+var matcher = language.NewMatcher(languages)
+
+// locale holds the language negotiated with the user. It is used to translate
+// the messages, format amounts and sort players' names.
+type locale struct {
+	tag language.Tag
+	p   *message.Printer
+}
+
+func newLocale(tag language.Tag) *locale {
+	return &locale{tag: tag, p: message.NewPrinter(tag)}
+}
+
+// negotiate returns the locale of the user. It is the one selected using the
+// "lang" URL parameter, which is remembered in a cookie, or the one negotiated
+// using the Accept-Language header.
+func negotiate(w http.ResponseWriter, r *http.Request) *locale {
+	lang := r.URL.Query().Get("lang")
+	if lang != "" {
+		http.SetCookie(w, &http.Cookie{Name: "lang", Value: lang, Path: "/", MaxAge: 365 * 24 * 3600})
+	} else if c, err := r.Cookie("lang"); err == nil {
+		lang = c.Value
+	}
+	tag, _ := language.MatchStrings(matcher, lang, r.Header.Get("Accept-Language"))
+	return newLocale(tag)
+}
+
+// lang returns the ISO 639 code of the locale's language.
+func (l *locale) lang() string {
+	base, _ := l.tag.Base()
+	return base.String()
+}
+
+// translate returns the translated message of the error. Only the messages
+// of players.Error can be translated, the others are returned as is.
+func (l *locale) translate(err error) string {
+	e, ok := err.(*players.Error)
+	if !ok {
+		return err.Error()
+	}
+	return l.p.Sprintf(e.Format, l.args(e.Args)...)
+}
+
+// errorf returns an error whose message is translated. The arguments which
+// are errors are translated too.
+func (l *locale) errorf(format string, args ...interface{}) error {
+	return errors.New(l.p.Sprintf(format, l.args(args)...))
+}
+
+// error returns the error with its message translated.
+func (l *locale) error(err error) error {
+	return errors.New(l.translate(err))
+}
+
+// args returns the arguments, with the errors replaced by their translated
+// message.
+func (l *locale) args(args []interface{}) []interface{} {
+	ret := make([]interface{}, len(args))
+	for i, arg := range args {
+		if err, ok := arg.(error); ok {
+			arg = l.translate(err)
+		}
+		ret[i] = arg
+	}
+	return ret
+}
+
+// amount formats an amount of money, grouping the digits.
+func (l *locale) amount(m players.Money) string {
+	return m.Text(players.DecimalSeparator(l.tag), players.GroupSeparator(l.tag))
+}
+
+// input formats an amount of money to be edited in a form: without group
+// separators.
+func (l *locale) input(m players.Money) string {
+	return m.Text(players.DecimalSeparator(l.tag), "")
+}
+
+// signed formats an amount of money like amount(), with its sign even if it
+// is positive.
+func (l *locale) signed(m players.Money) string {
+	if m.Sign() > 0 {
+		return "+" + l.amount(m)
+	}
+	return l.amount(m)
+}
+
+// percent formats the ratio of m to total as a percentage, e.g. "12.5%". It
+// returns an empty string if the total is zero.
+func (l *locale) percent(m, total players.Money) string {
+	if total.IsZero() {
+		return ""
+	}
+	return l.p.Sprintf("%.1f%%", 100*m.Float64()/total.Float64())
+}
+
+// execute applies the template to the data, translating the messages and
+// formatting the amounts according to the locale.
+func (l *locale) execute(w io.Writer, t *template.Template, data interface{}) error {
+	t, err := t.Clone()
+	if err != nil {
+		return err
+	}
+	return t.Funcs(template.FuncMap{
+		"T":      l.p.Sprintf,
+		"Amount": l.amount,
+		"Input":  l.input,
+		"Lang":   l.lang,
+		"Sorted": func(p players.Players) players.Players {
+			return sorted(p, l.tag)
+		},
+		"Debtors": func(d players.Debts) []string {
+			return d.Debtors(l.tag)
+		},
+		"SortBy": func(p players.Players, order string) players.Players {
+			return sortBy(p, order, l.tag)
+		},
+		"Signed":  l.signed,
+		"Percent": l.percent,
+		"Field": func(form url.Values, errs players.FieldErrors, name string, def interface{}) field {
+			f := newField(form, errs, name, def)
+			if err := errs[name]; err != nil {
+				f.Error = l.translate(err)
+			}
+			return f
+		},
+	}).Execute(w, data)
+}
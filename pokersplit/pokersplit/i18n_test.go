@@ -0,0 +1,136 @@
+package pokersplit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+	"golang.org/x/text/language"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		desc           string
+		url            string
+		cookie         string
+		acceptLanguage string
+		want           string
+	}{
+		{
+			desc: "default",
+			url:  "/",
+			want: "en",
+		},
+		{
+			desc:           "accept_language",
+			url:            "/",
+			acceptLanguage: "de-CH,de;q=0.9,en;q=0.8",
+			want:           "de",
+		},
+		{
+			desc:           "unsupported_accept_language",
+			url:            "/",
+			acceptLanguage: "it-CH",
+			want:           "en",
+		},
+		{
+			desc:           "cookie",
+			url:            "/",
+			cookie:         "fr",
+			acceptLanguage: "de-CH",
+			want:           "fr",
+		},
+		{
+			desc:           "url_parameter",
+			url:            "/?lang=de",
+			cookie:         "fr",
+			acceptLanguage: "fr-CH",
+			want:           "de",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, c.url, nil)
+			if c.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "lang", Value: c.cookie})
+			}
+			if c.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", c.acceptLanguage)
+			}
+			if got := negotiate(httptest.NewRecorder(), r).lang(); got != c.want {
+				t.Errorf("negotiate() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestLocaleErrorf(t *testing.T) {
+	l := newLocale(language.French)
+	inner := &players.Error{Format: "player %q not found", Args: []interface{}{"alice"}}
+	got := l.errorf("failed to remove player: %v", inner).Error()
+	want := `impossible de retirer le joueur : joueur "alice" introuvable`
+	if got != want {
+		t.Errorf("locale.errorf() = %q, want %q", got, want)
+	}
+
+	// Errors which can't be translated are left as is.
+	got = l.errorf("failed to decode players: %v", errors.New("unexpected EOF")).Error()
+	want = "impossible de décoder les joueurs : unexpected EOF"
+	if got != want {
+		t.Errorf("locale.errorf() = %q, want %q", got, want)
+	}
+}
+
+func TestLocaleAmount(t *testing.T) {
+	cases := []struct {
+		tag  language.Tag
+		want string
+	}{
+		{tag: language.English, want: "1,234.50"},
+		{tag: language.French, want: "1 234,50"},
+		{tag: language.MustParse("de-CH"), want: "1’234.50"},
+	}
+	for _, c := range cases {
+		if got := newLocale(c.tag).amount(players.Cents(123450)); got != c.want {
+			t.Errorf("locale(%v).amount() = %q, want %q", c.tag, got, c.want)
+		}
+	}
+}
+
+func TestLocaleInput(t *testing.T) {
+	cases := []struct {
+		tag    language.Tag
+		amount players.Money
+		want   string
+	}{
+		{tag: language.English, amount: players.Cents(123450), want: "1234.50"},
+		{tag: language.German, amount: players.Cents(123405), want: "1234,05"},
+		{tag: language.MustParse("de-CH"), amount: players.Cents(5), want: "0.05"},
+		{tag: language.French, amount: players.Cents(-1250), want: "-12,50"},
+	}
+	for _, c := range cases {
+		if got := newLocale(c.tag).input(c.amount); got != c.want {
+			t.Errorf("locale(%v).input(%v) = %q, want %q", c.tag, c.amount, got, c.want)
+		}
+	}
+}
+
+func TestLocalePercent(t *testing.T) {
+	cases := []struct {
+		tag   language.Tag
+		m     players.Money
+		total players.Money
+		want  string
+	}{
+		{tag: language.English, m: players.Cents(1250), total: players.Cents(10000), want: "12.5%"},
+		{tag: language.German, m: players.Cents(-1000), total: players.Cents(3000), want: "-33,3%"},
+		{tag: language.English, m: players.Cents(1000), want: ""},
+	}
+	for _, c := range cases {
+		if got := newLocale(c.tag).percent(c.m, c.total); got != c.want {
+			t.Errorf("locale(%v).percent(%v, %v) = %q, want %q", c.tag, c.m, c.total, got, c.want)
+		}
+	}
+}
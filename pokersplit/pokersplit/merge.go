@@ -0,0 +1,65 @@
+package pokersplit
+
+import (
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+	"golang.org/x/text/language"
+)
+
+// mergeRow compares a player across the three versions of a game involved in
+// a conflict. Any of the versions may be nil, if the player isn't part of it.
+type mergeRow struct {
+	Name string
+	// Base is the player as it was when the user started editing.
+	Base *players.Player
+	// Theirs is the player as saved concurrently by someone else.
+	Theirs *players.Player
+	// Mine is the player as submitted by the user.
+	Mine *players.Player
+	// BuyInDiverged and StackDiverged are true when the concurrent edits
+	// resulted in different values.
+	BuyInDiverged bool
+	StackDiverged bool
+}
+
+// mergeRows returns a mergeRow for every player of the given versions of a
+// game, sorted by name according to the language.
+func mergeRows(base, theirs, mine players.Players, tag language.Tag) []mergeRow {
+	rows := make(map[string]*mergeRow)
+	var all players.Players
+	row := func(p *players.Player) *mergeRow {
+		r, ok := rows[p.Name]
+		if !ok {
+			r = &mergeRow{Name: p.Name}
+			rows[p.Name] = r
+			all = append(all, p)
+		}
+		return r
+	}
+	for _, p := range base {
+		row(p).Base = p
+	}
+	for _, p := range theirs {
+		row(p).Theirs = p
+	}
+	for _, p := range mine {
+		row(p).Mine = p
+	}
+
+	var ret []mergeRow
+	for _, p := range sorted(all, tag) {
+		r := rows[p.Name]
+		var theirBuyIn, theirStack, myBuyIn, myStack players.Money
+		if r.Theirs != nil {
+			theirBuyIn, theirStack = r.Theirs.BuyIn, r.Theirs.Stack
+		}
+		if r.Mine != nil {
+			myBuyIn, myStack = r.Mine.BuyIn, r.Mine.Stack
+		}
+		// A player added or removed by only one of the two edits diverges too.
+		presenceDiverged := (r.Theirs == nil) != (r.Mine == nil)
+		r.BuyInDiverged = presenceDiverged || !theirBuyIn.Equal(myBuyIn)
+		r.StackDiverged = presenceDiverged || !theirStack.Equal(myStack)
+		ret = append(ret, *r)
+	}
+	return ret
+}
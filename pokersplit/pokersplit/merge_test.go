@@ -0,0 +1,31 @@
+package pokersplit
+
+import (
+	"testing"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/text/language"
+)
+
+func TestMergeRows(t *testing.T) {
+	alice := &players.Player{Name: "alice", BuyIn: players.Cents(1000)}
+	aliceRebuy := &players.Player{Name: "alice", BuyIn: players.Cents(2000)}
+	bob := &players.Player{Name: "bob", BuyIn: players.Cents(1000)}
+	bobStack := &players.Player{Name: "bob", BuyIn: players.Cents(1000), Stack: players.Cents(500)}
+	charlie := &players.Player{Name: "charlie", BuyIn: players.Cents(500)}
+
+	base := players.Players{alice, bob}
+	theirs := players.Players{aliceRebuy, bob, charlie}
+	mine := players.Players{alice, bobStack}
+
+	want := []mergeRow{
+		{Name: "alice", Base: alice, Theirs: aliceRebuy, Mine: alice, BuyInDiverged: true},
+		{Name: "bob", Base: bob, Theirs: bob, Mine: bobStack, StackDiverged: true},
+		{Name: "charlie", Theirs: charlie, BuyInDiverged: true, StackDiverged: true},
+	}
+	got := mergeRows(base, theirs, mine, language.English)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("mergeRows() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,104 @@
+package pokersplit
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+)
+
+//go:embed net.tmpl
+var netPage string
+
+var netTmpl = template.Must(template.New("net").Funcs(funcs).Parse(netPage))
+
+type netTmplData struct {
+	// Games are the URLs or IDs of the games, one per line, as submitted.
+	Games   string
+	Players players.Players
+	Debts   players.Debts
+	// Payments holds the QR codes to pay the debts, in the same order. It's
+	// nil if the games aren't played in the same currency.
+	Payments map[string][]payment
+	Error    error
+}
+
+// ServeNet settles the unpaid debts of several games at once. The games are
+// given in the "games" URL parameter, one per line, either by their URL or by
+// their ID if they were saved since the server started.
+func ServeNet(w http.ResponseWriter, r *http.Request) {
+	l := negotiate(w, r)
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		l.execute(w, netTmpl, netTmplData{Error: l.errorf("unsupported HTTP method: %s", r.Method)})
+		return
+	}
+	tData := netTmplData{Games: r.URL.Query().Get("games")}
+	var games []players.Players
+	var currencies []string
+	for _, ref := range strings.Fields(tData.Games) {
+		g, err := loadGame(ref)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			tData.Error = l.errorf("failed to load game %q: %v", ref, err)
+			l.execute(w, netTmpl, tData)
+			return
+		}
+		// Tell which game can't be settled, rather than its position.
+		if _, err := g.Players.CalculateDebts(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			tData.Error = l.errorf("failed to calculate the debts of game %q: %v", ref, err)
+			l.execute(w, netTmpl, tData)
+			return
+		}
+		games = append(games, g.Players)
+		currency := ""
+		if g.Settings != nil {
+			currency = g.Settings.Currency
+		}
+		if !contains(currencies, currency) {
+			currencies = append(currencies, currency)
+		}
+	}
+	if len(games) == 0 {
+		l.execute(w, netTmpl, tData)
+		return
+	}
+	p, err := players.Net(games)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		tData.Error = l.error(err)
+		l.execute(w, netTmpl, tData)
+		return
+	}
+	debts, err := p.CalculateDebts()
+	if err != nil {
+		tData.Error = l.errorf("failed to calculate debts: %v", err)
+		l.execute(w, netTmpl, tData)
+		return
+	}
+	tData.Players = p
+	tData.Debts = debts
+	// The payment requests are in the currency of the games, which must be
+	// the same for all of them.
+	if len(currencies) == 1 {
+		tData.Payments = payments(p, debts, currencies[0], l)
+	}
+	l.execute(w, netTmpl, tData)
+}
+
+// loadGame returns the game referred to by its URL, the encoded game found in
+// its URL, or its ID. Games referred to by their ID must have been saved since
+// the server started; their latest revision is returned.
+func loadGame(ref string) (*players.Game, error) {
+	if g, ok := games.get(ref); ok {
+		return g, nil
+	}
+	if u, err := url.Parse(ref); err == nil {
+		ref = u.Path
+	}
+	return players.GameFromBase64(ref[strings.LastIndex(ref, "/")+1:])
+}
@@ -0,0 +1,73 @@
+package pokersplit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+)
+
+func TestLoadGame(t *testing.T) {
+	g := &players.Game{ID: "0123456789abcdef", Revision: 2, Players: players.Players{{Name: "alice"}}}
+	data, err := g.ToBase64()
+	if err != nil {
+		t.Fatalf("ToBase64() returned an error: %v", err)
+	}
+	games.commit(g, 1)
+	for _, ref := range []string{g.ID, data, "https://example.com/" + data + "?unit=0.5", "https://example.com/history/" + data} {
+		got, err := loadGame(ref)
+		if err != nil {
+			t.Errorf("loadGame(%q) returned an error: %v", ref, err)
+			continue
+		}
+		if got.ID != g.ID || got.Revision != g.Revision {
+			t.Errorf("loadGame(%q) = game %q revision %d, want game %q revision %d", ref, got.ID, got.Revision, g.ID, g.Revision)
+		}
+	}
+	if _, err := loadGame("abc"); err == nil {
+		t.Errorf("loadGame() of an invalid game didn't return an error, but one was expected")
+	}
+}
+
+func TestServeNet(t *testing.T) {
+	var refs []string
+	for _, p := range []players.Players{
+		{{Name: "alice", BuyIn: players.Cents(1000), Stack: players.Cents(2000)}, {Name: "bob", BuyIn: players.Cents(1000)}},
+		{{Name: "alice", BuyIn: players.Cents(1000), Stack: players.Cents(500)}, {Name: "bob", BuyIn: players.Cents(1000), Stack: players.Cents(1500)}},
+	} {
+		data, err := (&players.Game{Players: p}).ToBase64()
+		if err != nil {
+			t.Fatalf("ToBase64() returned an error: %v", err)
+		}
+		refs = append(refs, "https://example.com/"+data)
+	}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/net?"+url.Values{"games": []string{strings.Join(refs, "\n")}}.Encode(), nil)
+	ServeNet(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ServeNet() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if want := "5.00 to alice"; !strings.Contains(w.Body.String(), want) {
+		t.Errorf("ServeNet() body doesn't contain %q:\n%s", want, w.Body.String())
+	}
+}
+
+func TestServeNetUnsettleable(t *testing.T) {
+	data, err := (&players.Game{Players: players.Players{{Name: "alice", BuyIn: players.Cents(1000)}}}).ToBase64()
+	if err != nil {
+		t.Fatalf("ToBase64() returned an error: %v", err)
+	}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/net?"+url.Values{"games": []string{"https://example.com/" + data}}.Encode(), nil)
+	ServeNet(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("ServeNet() status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	// The game which can't be settled is named in the error.
+	if want := "https://example.com/" + data; !strings.Contains(w.Body.String(), want) {
+		t.Errorf("ServeNet() body doesn't contain %q:\n%s", want, w.Body.String())
+	}
+}
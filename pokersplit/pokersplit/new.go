@@ -0,0 +1,152 @@
+package pokersplit
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+)
+
+//go:embed new.tmpl
+var newPage string
+
+var newTmpl = template.Must(template.New("new").Funcs(funcs).Parse(newPage))
+
+// Presets are the presets new games can be started from by their name, e.g.
+// "/new?preset=thursday".
+var Presets map[string]*players.Preset
+
+type newTmplData struct {
+	Form   url.Values
+	Errors players.FieldErrors
+	// URL starts a new game from the preset described by the form, if it is
+	// valid.
+	URL string
+	// Presets are the names of the presets, sorted.
+	Presets []string
+	Error   error
+}
+
+// ServeNew starts a new game from the preset given in the "preset" URL
+// parameter, either by its name or encoded, and redirects to it. Without
+// preset, it displays a form to describe one, whose fields are also URL
+// parameters. The form is filled with the players and the settings of the
+// game given in the "game" URL parameter, by its URL or by its ID, if any.
+func ServeNew(w http.ResponseWriter, r *http.Request) {
+	l := negotiate(w, r)
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		l.execute(w, newTmpl, newTmplData{Error: l.errorf("unsupported HTTP method: %s", r.Method)})
+		return
+	}
+	q := r.URL.Query()
+	tData := newTmplData{Form: q}
+	for name := range Presets {
+		tData.Presets = append(tData.Presets, name)
+	}
+	sort.Strings(tData.Presets)
+
+	if ref := q.Get("preset"); ref != "" {
+		p, err := loadPreset(ref)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			tData.Error = l.errorf("failed to load preset %q: %v", ref, err)
+			l.execute(w, newTmpl, tData)
+			return
+		}
+		data, err := startGame(p, r)
+		if err != nil {
+			tData.Error = l.error(err)
+			l.execute(w, newTmpl, tData)
+			return
+		}
+		w.Header().Set("Location", gameURL(r, data))
+		w.WriteHeader(http.StatusSeeOther)
+		return
+	}
+
+	var p *players.Preset
+	var err error
+	switch {
+	case q.Get("game") != "":
+		g, loadErr := loadGame(q.Get("game"))
+		if loadErr != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			tData.Error = l.errorf("failed to load game %q: %v", q.Get("game"), loadErr)
+			l.execute(w, newTmpl, tData)
+			return
+		}
+		p = g.Preset()
+		tData.Form = presetForm(p, l)
+	case q.Get("names") != "" || q.Get("buyin") != "" || q.Get("currency") != "" || q.Get("chips") != "":
+		p, err = players.PresetFromForm(q, l.tag)
+		if fieldErrs, ok := err.(players.FieldErrors); ok {
+			tData.Error = l.errorf("some values are invalid, please correct them")
+			tData.Errors = fieldErrs
+		} else if err != nil {
+			tData.Error = l.error(err)
+		}
+	}
+	if p != nil {
+		data, err := p.ToBase64()
+		if err != nil {
+			tData.Error = l.errorf("failed to encode preset: %v", err)
+		} else {
+			u := baseURL(r)
+			u.Path = "/new"
+			u.RawQuery = url.Values{"preset": {data}}.Encode()
+			tData.URL = u.String()
+		}
+	}
+	l.execute(w, newTmpl, tData)
+}
+
+// loadPreset returns the preset with the given name or, if there is none, the
+// encoded preset.
+func loadPreset(ref string) (*players.Preset, error) {
+	if p, ok := Presets[ref]; ok {
+		return p, nil
+	}
+	return players.PresetFromBase64(ref)
+}
+
+// startGame saves a new game started from the preset, and returns it encoded.
+func startGame(p *players.Preset, r *http.Request) (string, error) {
+	g, err := p.Game()
+	if err != nil {
+		return "", err
+	}
+	g.Revision = 1
+	g.History = []players.Edit{{
+		Revision: g.Revision,
+		Time:     time.Now().UTC().Truncate(time.Second),
+		Editor:   editor(r),
+		Changes:  append(players.Diff(nil, g.Players), players.DiffSettings(nil, g.Settings)...),
+	}}
+	games.commit(g, 0)
+	return g.ToBase64()
+}
+
+// presetForm returns the values of the form describing the preset.
+func presetForm(p *players.Preset, l *locale) url.Values {
+	ret := url.Values{"names": {strings.Join(p.Names, "\n")}}
+	if !p.BuyIn.IsZero() {
+		ret.Set("buyin", l.input(p.BuyIn))
+	}
+	if p.Currency != "" {
+		ret.Set("currency", p.Currency)
+	}
+	var chips []string
+	for _, chip := range p.Chips {
+		chips = append(chips, l.input(chip))
+	}
+	if len(chips) > 0 {
+		ret.Set("chips", strings.Join(chips, " "))
+	}
+	return ret
+}
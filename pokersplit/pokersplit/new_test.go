@@ -0,0 +1,54 @@
+package pokersplit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+)
+
+func TestServeNew(t *testing.T) {
+	Presets = map[string]*players.Preset{
+		"thursday": {Names: []string{"alice", "bob"}, Settings: players.Settings{BuyIn: players.Cents(2000), Currency: "CHF"}},
+	}
+	defer func() { Presets = nil }()
+
+	w := httptest.NewRecorder()
+	ServeNew(w, httptest.NewRequest(http.MethodGet, "/new?preset=thursday", nil))
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("ServeNew() status = %d, want %d", w.Code, http.StatusSeeOther)
+	}
+	loc := w.Header().Get("Location")
+	g, err := players.GameFromBase64(loc[strings.LastIndex(loc, "/")+1:])
+	if err != nil {
+		t.Fatalf("failed to decode the game ServeNew() redirected to: %v", err)
+	}
+	if len(g.Players) != 2 || !g.Players.BuyIn().Equal(players.Cents(4000)) || g.Revision != 1 || len(g.History) != 1 {
+		t.Errorf("ServeNew() started game %+v, want 2 players who bought in for 20.00 at revision 1", g)
+	}
+	if g.Settings == nil || g.Settings.Currency != "CHF" {
+		t.Errorf("ServeNew() started game with settings %+v, want the preset's", g.Settings)
+	}
+	// The standard buy-in is recorded in the history, so that it can be undone
+	// like later changes.
+	if changes := g.History[0].Changes; len(changes) != 5 || changes[4] != (players.Change{Field: players.FieldStandardBuyIn, New: "20.00"}) {
+		t.Errorf("ServeNew() recorded changes %+v, want the players and the standard buy-in", changes)
+	}
+
+	w = httptest.NewRecorder()
+	ServeNew(w, httptest.NewRequest(http.MethodGet, "/new?preset=friday", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("ServeNew() of an unknown preset status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	w = httptest.NewRecorder()
+	ServeNew(w, httptest.NewRequest(http.MethodGet, "/new?names=alice,bob&buyin=20", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("ServeNew() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if want := "/new?preset="; !strings.Contains(w.Body.String(), want) {
+		t.Errorf("ServeNew() body doesn't contain %q:\n%s", want, w.Body.String())
+	}
+}
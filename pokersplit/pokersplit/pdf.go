@@ -0,0 +1,132 @@
+package pokersplit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Dimensions of an A4 page, in points.
+const (
+	pageWidth  = 595
+	pageHeight = 842
+)
+
+// pdf is a minimal PDF writer, able to write text and lines on A4 pages using
+// the standard Helvetica fonts, which PDF readers provide. The text is
+// encoded in Windows-1252, which covers the languages of the user interface.
+type pdf struct {
+	// pages holds the content stream of each page.
+	pages []*bytes.Buffer
+}
+
+// addPage starts a new page, on which the next text and lines are drawn.
+func (d *pdf) addPage() {
+	d.pages = append(d.pages, new(bytes.Buffer))
+}
+
+// page returns the content stream of the current page.
+func (d *pdf) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// text writes s with its baseline starting at (x, y), y being measured from
+// the top of the page.
+func (d *pdf) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, pageHeight-y, pdfString(s))
+}
+
+// textRight writes s so that it ends at x.
+func (d *pdf) textRight(x, y, size float64, bold bool, s string) {
+	d.text(x-textWidth(s, size), y, size, bold, s)
+}
+
+// line draws a thin line from (x1, y1) to (x2, y2).
+func (d *pdf) line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(d.page(), "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, pageHeight-y1, x2, pageHeight-y2)
+}
+
+// writeTo writes the document: the catalog, the page tree, the fonts and the
+// pages along with their content, followed by the cross-reference table.
+func (d *pdf) writeTo(w io.Writer) error {
+	d.page()
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(format string, args ...interface{}) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n", len(offsets))
+		fmt.Fprintf(&buf, format, args...)
+		buf.WriteString("\nendobj\n")
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// The pages and their content follow the first 4 objects.
+	var kids []string
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+2*i))
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, content := range d.pages {
+		object("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", pageWidth, pageHeight, 6+2*i)
+		object("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.Bytes())
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfString encodes s in Windows-1252, replacing the characters it can't
+// represent by "?", and escapes it to be used in a PDF string literal.
+func pdfString(s string) string {
+	var ret strings.Builder
+	for _, r := range s {
+		b, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			b = '?'
+		}
+		if b == '\\' || b == '(' || b == ')' {
+			ret.WriteByte('\\')
+		}
+		ret.WriteByte(b)
+	}
+	return ret.String()
+}
+
+// helveticaWidths are the widths of the characters of the Helvetica font
+// which are used in amounts, in thousandths of the font size. The other ones
+// are assumed to be as wide as a digit.
+var helveticaWidths = map[rune]float64{
+	' ': 278, '\u00a0': 278, '\'': 191, '’': 222, '+': 584, ',': 278, '-': 333, '.': 278,
+}
+
+// textWidth returns the approximate width of s written in Helvetica.
+func textWidth(s string, size float64) float64 {
+	var ret float64
+	for _, r := range s {
+		w, ok := helveticaWidths[r]
+		if !ok {
+			w = 556
+		}
+		ret += w
+	}
+	return ret * size / 1000
+}
@@ -0,0 +1,84 @@
+package pokersplit
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPDFString(t *testing.T) {
+	cases := []struct {
+		s    string
+		want string
+	}{
+		{s: "alice", want: "alice"},
+		{s: `a (b) \c`, want: `a \(b\) \\c`},
+		{s: "Zürich", want: "Z\xfcrich"},
+		{s: "1’234.50", want: "1\x92234.50"},
+		{s: "🃏", want: "?"},
+	}
+	for _, c := range cases {
+		if got := pdfString(c.s); got != c.want {
+			t.Errorf("pdfString(%q) = %q, want %q", c.s, got, c.want)
+		}
+	}
+}
+
+func TestPDFWriteTo(t *testing.T) {
+	var d pdf
+	d.text(50, 50, 12, false, "first page")
+	d.addPage()
+	d.text(50, 50, 12, true, "second page")
+	d.line(50, 60, 100, 60)
+	var buf bytes.Buffer
+	if err := d.writeTo(&buf); err != nil {
+		t.Fatalf("pdf.writeTo() returned an error: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "%PDF-1.4\n") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Errorf("pdf.writeTo() = %q, want a PDF document", out)
+	}
+	if want := "/Count 2"; !strings.Contains(out, want) {
+		t.Errorf("pdf.writeTo() = %q, want it to contain %q", out, want)
+	}
+
+	// The cross-reference table must point to the objects.
+	m := regexp.MustCompile(`(?s)xref\n0 (\d+)\n0000000000 65535 f \n(.*)trailer`).FindStringSubmatch(out)
+	if m == nil {
+		t.Fatalf("pdf.writeTo() = %q, want a cross-reference table", out)
+	}
+	entries := strings.Split(strings.TrimSuffix(m[2], "\n"), "\n")
+	if size, _ := strconv.Atoi(m[1]); len(entries) != size-1 {
+		t.Fatalf("cross-reference table has %d entries, want %d", len(entries), size-1)
+	}
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil {
+			t.Fatalf("invalid cross-reference entry %q: %v", entry, err)
+		}
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(out[offset:], want) {
+			t.Errorf("cross-reference entry %d points to %q, want %q", i+1, out[offset:offset+len(want)], want)
+		}
+	}
+	if m := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(out); m == nil || !strings.HasPrefix(out[mustAtoi(t, m[1]):], "xref\n") {
+		t.Errorf("startxref doesn't point to the cross-reference table")
+	}
+}
+
+func TestTextWidth(t *testing.T) {
+	if got, want := textWidth("+1,000.50", 10), (584+556+278+556*3+278+556*2)/100.0; got != want {
+		t.Errorf("textWidth() = %v, want %v", got, want)
+	}
+}
+
+func mustAtoi(t *testing.T, s string) int {
+	t.Helper()
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		t.Fatalf("strconv.Atoi(%q) returned an error: %v", s, err)
+	}
+	return n
+}
@@ -5,38 +5,123 @@ import (
 	_ "embed"
 	"fmt"
 	"html/template"
+	"net"
 	"net/http"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fhchstr/pokersplit/pokersplit/players"
-	"golang.org/x/text/collate"
 	"golang.org/x/text/language"
 )
 
 //go:embed index.tmpl
 var index string
 
+//go:embed merge.tmpl
+var merge string
+
 var (
-	tmpl = template.Must(template.New("index").Funcs(template.FuncMap{
-		// Cents converts the cents to a full currency unit.
-		"Cents": func(cents int) float64 {
-			return float64(cents) / 100
+	funcs = template.FuncMap{
+		"Field":   newField,
+		"Methods": newMethods,
+		"Static":  staticPath,
+		// MethodLabel returns the label of the payment method.
+		"MethodLabel": func(method string) string {
+			return methodLabels[method]
 		},
-		// Iterate returns a slice of the given length. The items' value is their index.
-		"Iterate": func(i int) []int {
-			var ret []int
-			for j := 0; j < i; j++ {
-				ret = append(ret, j)
-			}
-			return ret
+		// The following functions depend on the user's locale. Their
+		// implementation is replaced by locale.execute().
+		"T":      fmt.Sprintf,
+		"Amount": newLocale(language.English).amount,
+		"Input":  newLocale(language.English).input,
+		"Lang":   newLocale(language.English).lang,
+		"Sorted": func(p players.Players) players.Players {
+			return sorted(p, language.English)
+		},
+		"Debtors": func(d players.Debts) []string {
+			return d.Debtors(language.English)
 		},
-		"Sorted": sorted,
-	}).Parse(index))
+		"SortBy": func(p players.Players, order string) players.Players {
+			return sortBy(p, order, language.English)
+		},
+		"Signed":  newLocale(language.English).signed,
+		"Percent": newLocale(language.English).percent,
+	}
+	tmpl      = template.Must(template.New("index").Funcs(funcs).Parse(index))
+	mergeTmpl = template.Must(template.New("merge").Funcs(funcs).Parse(merge))
+
+	games = newStore(storeSize)
+
+	// TrustedProxies are the IP addresses of the reverse proxies in front of
+	// the server, whose X-Forwarded-For header is trusted.
+	TrustedProxies []string
+
+	// Rows is the number of blank rows of the form of a new game, which must not
+	// be negative. It can be overridden using the "rows" URL parameter.
+	Rows = 7
+
+	// units are the granularities the transfers can be rounded to.
+	units = []players.Money{players.Cents(5), players.Cents(10), players.Cents(50), players.Cents(100), players.Cents(500), players.Cents(1000)}
 )
 
-// sorted returns the Players sorted by name, ignoring case and accents.
-func sorted(p players.Players) players.Players {
+// sortOrders are the orders in which the players can be listed, the first one
+// being the default.
+var sortOrders = []string{"name", "net", "buyin"}
+
+// sortBy returns the Players sorted according to the order: by name, by net
+// result or by buy-in, the largest first. Players having the same net result
+// or buy-in are sorted by name.
+func sortBy(p players.Players, order string, tag language.Tag) players.Players {
+	ret := sorted(p, tag)
+	switch order {
+	case "net":
+		sort.SliceStable(ret, func(i, j int) bool {
+			return ret[i].Net().Cmp(ret[j].Net()) > 0
+		})
+	case "buyin":
+		sort.SliceStable(ret, func(i, j int) bool {
+			return ret[i].BuyIn.Cmp(ret[j].BuyIn) > 0
+		})
+	}
+	return ret
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// extremes returns the players who won the most and the ones who lost the
+// most. There are several of them in case of a tie, and none if nobody won, or
+// lost.
+func extremes(p players.Players) (winners, loosers players.Players) {
+	var most, least players.Money
+	for _, player := range p {
+		switch net := player.Net(); {
+		case net.Sign() > 0 && net.Cmp(most) > 0:
+			most, winners = net, players.Players{player}
+		case net.Sign() > 0 && net.Equal(most):
+			winners = append(winners, player)
+		case net.Sign() < 0 && net.Cmp(least) < 0:
+			least, loosers = net, players.Players{player}
+		case net.Sign() < 0 && net.Equal(least):
+			loosers = append(loosers, player)
+		}
+	}
+	return winners, loosers
+}
+
+// sorted returns the Players sorted by name according to the language,
+// ignoring case and accents, the same way their names are compared to detect
+// duplicates.
+func sorted(p players.Players, tag language.Tag) players.Players {
 	playersByName := make(map[string]*players.Player)
 	for _, player := range p {
 		playersByName[player.Name] = player
@@ -46,7 +131,7 @@ func sorted(p players.Players) players.Players {
 	for name := range playersByName {
 		playerNames = append(playerNames, name)
 	}
-	cl := collate.New(language.English, collate.IgnoreCase, collate.IgnoreDiacritics)
+	cl := players.NewCollator(tag)
 	cl.SortStrings(playerNames)
 
 	ret := make(players.Players, len(p))
@@ -56,71 +141,534 @@ func sorted(p players.Players) players.Players {
 	return ret
 }
 
+// field holds what's needed to render an input field of the form.
+type field struct {
+	Name  string
+	Value string
+	// Error is the reason why the value is invalid, if it is.
+	Error string
+}
+
+// newField returns the field with the given name. Its value is the one
+// submitted in the form or, if it wasn't, the given default value.
+func newField(form url.Values, errs players.FieldErrors, name string, def interface{}) field {
+	f := field{Name: name, Value: fmt.Sprint(def)}
+	if err := errs[name]; err != nil {
+		f.Error = err.Error()
+	}
+	if v, ok := form[name]; ok && len(v) > 0 {
+		f.Value = v[0]
+	}
+	return f
+}
+
+// methodLabels maps the payment methods to their label.
+var methodLabels = map[string]string{
+	players.MethodCash:   "Cash",
+	players.MethodMobile: "Mobile payment",
+}
+
+// methodOption holds what's needed to render the checkbox of a payment
+// method.
+type methodOption struct {
+	// Name of the field, shared by all the payment methods of a player.
+	Name    string
+	Method  string
+	Checked bool
+}
+
+// newMethods returns the checkboxes of the payment methods field with the
+// given name. They are checked according to the submitted form or, if it
+// wasn't, the given default methods.
+func newMethods(form url.Values, name string, def []string) []methodOption {
+	checked := def
+	if form != nil {
+		checked = form[name]
+	}
+	var ret []methodOption
+	for _, m := range players.Methods {
+		o := methodOption{Name: name, Method: m}
+		for _, c := range checked {
+			if c == m {
+				o.Checked = true
+			}
+		}
+		ret = append(ret, o)
+	}
+	return ret
+}
+
 type tmplData struct {
+	// Data is the encoded game, as found in the URL.
+	Data string
+	// ShareQR is false if the link to share the game is too long to be
+	// encoded in a QR code.
+	ShareQR bool
+	Game    *players.Game
 	Players players.Players
 	Debts   players.Debts
-	Error   error
+	// Adjustments maps the players whose balance was rounded to settle the
+	// debts to the amount added to it.
+	Adjustments map[string]players.Money
+	// Payments holds the QR codes to pay the debts, in the same order.
+	Payments map[string][]payment
+	// Outstanding maps the debtors to the total of the debts they didn't pay
+	// yet.
+	Outstanding map[string]players.Money
+	// Summary is the plain-text summary of the game, to paste in a chat.
+	Summary string
+	// Unit is the granularity the transfers are rounded to, as found in the
+	// URL. It is empty if they aren't rounded.
+	Unit string
+	// Units are the granularities the user can choose from.
+	Units []players.Money
+	// Rows is the number of blank rows, as found in the URL.
+	Rows string
+	// BlankRows are the indices of the form's rows to add players, following
+	// the rows of the players.
+	BlankRows []int
+	// Sort is the order in which the players are listed, as found in the URL.
+	// It is empty if they are listed by name.
+	Sort string
+	// Winners and Loosers are the players who won, or lost, the most.
+	Winners players.Players
+	Loosers players.Players
+	// Editor is the name of the person editing the game, if known.
+	Editor string
+	// Form holds the values submitted by the user, if they must be displayed
+	// again because some of them are invalid.
+	Form url.Values
+	// Errors holds the reason why the fields of the form are invalid.
+	Errors players.FieldErrors
+	Error  error
+}
+
+// Currency returns the currency of the game, if set.
+func (d tmplData) Currency() string {
+	if d.Game == nil || d.Game.Settings == nil {
+		return ""
+	}
+	return d.Game.Settings.Currency
+}
+
+// StandardBuyIn returns the standard buy-in of the game, which is zero if it
+// isn't set.
+func (d tmplData) StandardBuyIn() players.Money {
+	if d.Game == nil || d.Game.Settings == nil {
+		return players.Money{}
+	}
+	return d.Game.Settings.BuyIn
+}
+
+// maxRows is the maximum number of blank rows of the form.
+const maxRows = 100
+
+// blankRows returns the indices of the blank rows of the form, following the
+// n rows of the players. There is a single one, or Rows for a new game, unless
+// another number, which may be zero, is set using the "rows" URL parameter.
+// There are enough of them to display the rows submitted in the form, if any.
+func blankRows(r *http.Request, n int, form url.Values) []int {
+	count := 1
+	if n == 0 {
+		count = Rows
+	}
+	if rows, err := strconv.Atoi(r.URL.Query().Get("rows")); err == nil && rows >= 0 {
+		count = rows
+	}
+	for k := range form {
+		if !strings.HasPrefix(k, "player") {
+			continue
+		}
+		if i, err := strconv.Atoi(strings.TrimPrefix(k, "player")); err == nil && i+1-n > count {
+			count = i + 1 - n
+		}
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > maxRows {
+		count = maxRows
+	}
+	var ret []int
+	for i := n; i < n+count; i++ {
+		ret = append(ret, i)
+	}
+	return ret
+}
+
+// MoreRows returns the number of blank rows of the form once more of them are
+// added.
+func (d tmplData) MoreRows() string {
+	return strconv.Itoa(len(d.BlankRows) + 5)
+}
+
+// Highlight returns the class of the table row of the player, highlighting
+// the ones who won, or lost, the most.
+func (d tmplData) Highlight(name string) string {
+	for _, player := range d.Winners {
+		if player.Name == name {
+			return "table-success"
+		}
+	}
+	for _, player := range d.Loosers {
+		if player.Name == name {
+			return "table-danger"
+		}
+	}
+	return ""
+}
+
+// URL returns the URL of the game's page with the given query parameter set,
+// keeping the other ones: the rounding unit, the sort order and the number of
+// blank rows. Setting a parameter to an empty value removes it.
+func (d tmplData) URL(key, value string) string {
+	q := url.Values{}
+	for k, v := range map[string]string{"unit": d.Unit, "sort": d.Sort, "rows": d.Rows, key: value} {
+		if v != "" {
+			q.Set(k, v)
+		}
+	}
+	if len(q) == 0 {
+		return "?"
+	}
+	return "?" + q.Encode()
 }
 
 func ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l := negotiate(w, r)
 	var err error
 	switch r.Method {
 	case http.MethodGet:
-		err = show(w, r)
+		err = show(w, r, l)
 	case http.MethodPost:
-		err = update(w, r)
+		err = update(w, r, l)
 	default:
 		w.WriteHeader(http.StatusMethodNotAllowed)
-		err = fmt.Errorf("unsupported HTTP method: %s", r.Method)
+		err = l.errorf("unsupported HTTP method: %s", r.Method)
 	}
 	if err != nil {
-		tmpl.Execute(w, tmplData{Error: err})
+		l.execute(w, tmpl, tmplData{Error: err})
 	}
 }
 
-func show(w http.ResponseWriter, r *http.Request) error {
-	var tData tmplData
-	p, err := players.FromBase64(strings.TrimPrefix(r.URL.Path, "/"))
+type mergeTmplData struct {
+	Rows []mergeRow
+	// Theirs is the latest revision of the game, encoded.
+	Theirs string
+	// Revision is the number of the latest revision of the game.
+	Revision int
+	// Mine are the players submitted by the user.
+	Mine players.Players
+}
+
+func show(w http.ResponseWriter, r *http.Request, l *locale) error {
+	tData := tmplData{Editor: editorName(r)}
+	g, err := players.GameFromBase64(strings.TrimPrefix(r.URL.Path, "/"))
 	if err != nil {
-		tData.Error = fmt.Errorf("failed to decode players: %v", err)
+		tData.Error = l.errorf("failed to decode players: %v", err)
+		g = &players.Game{}
+	} else {
+		tData.Data = strings.TrimPrefix(r.URL.Path, "/")
+		tData.ShareQR = len(shareURL(r, g, tData.Data)) <= maxQRText
 	}
+	p := g.Players
+	tData.Game = g
 	tData.Players = p
-	if p.BuyIn() == p.Stack() {
-		debts, err := p.CalculateDebts()
+	tData.Units = units
+	tData.Winners, tData.Loosers = extremes(p)
+	tData.Rows = r.URL.Query().Get("rows")
+	tData.BlankRows = blankRows(r, len(p), nil)
+	if tData.Sort = r.URL.Query().Get("sort"); tData.Sort != "" && !contains(sortOrders, tData.Sort) {
+		tData.Error = l.errorf("unsupported sort order: %q", tData.Sort)
+	}
+	var opts players.SettleOptions
+	if tData.Unit = r.URL.Query().Get("unit"); tData.Unit != "" {
+		if opts.Unit, err = players.ParseMoney(tData.Unit); err != nil {
+			tData.Error = l.errorf("invalid rounding unit: %v", err)
+		}
+	}
+	if p.BuyIn().Equal(p.Stack()) {
+		s, err := p.Settle(opts)
 		if err != nil {
-			tData.Error = fmt.Errorf("failed to calculate debts: %v", err)
+			tData.Error = l.errorf("failed to calculate debts: %v", err)
+		} else {
+			tData.Debts = s.Debts
+			tData.Adjustments = s.Adjustments
+			tData.Payments = payments(p, s.Debts, tData.Currency(), l)
+			tData.Outstanding = s.Debts.Outstanding()
+			tData.Summary = summary(p, s.Debts, l)
 		}
-		tData.Debts = debts
 	}
-	return tmpl.Execute(w, tData)
+	return l.execute(w, tmpl, tData)
 }
 
-func update(w http.ResponseWriter, r *http.Request) error {
+func update(w http.ResponseWriter, r *http.Request, l *locale) error {
 	var tData tmplData
 	if err := r.ParseForm(); err != nil {
-		tData.Error = fmt.Errorf("failed to parse form: %v", err)
-		return tmpl.Execute(w, tData)
+		tData.Error = l.errorf("failed to parse form: %v", err)
+		return l.execute(w, tmpl, tData)
 	}
-	p, err := players.FromForm(r.PostForm)
+	base, err := players.GameFromBase64(strings.TrimPrefix(r.URL.Path, "/"))
 	if err != nil {
-		tData.Error = fmt.Errorf("failed to parse players from form: %v", err)
-		return tmpl.Execute(w, tData)
+		tData.Error = l.errorf("failed to decode players: %v", err)
+		return l.execute(w, tmpl, tData)
 	}
-	data, err := p.ToBase64()
+	tData.Game = base
+	tData.Players = base.Players
+	// The fields of the form are numbered in the order the players are
+	// listed, which must stay the same to display the invalid values.
+	tData.Sort = r.URL.Query().Get("sort")
+	revision, err := strconv.Atoi(r.PostForm.Get("revision"))
 	if err != nil {
-		tData.Error = fmt.Errorf("failed to encode players: %v", err)
-		return tmpl.Execute(w, tData)
+		tData.Error = l.errorf("missing or invalid revision: %v", err)
+		return l.execute(w, tmpl, tData)
 	}
+	if revision != base.Revision {
+		tData.Error = l.errorf("revision %d doesn't match the game's revision %d", revision, base.Revision)
+		return l.execute(w, tmpl, tData)
+	}
+	p, settings, edit, err := revise(base, r.PostForm, l)
+	if err != nil {
+		tData.Error = err
+		// Display the invalid values next to their field, so that the user can
+		// fix them without having to type everything again.
+		if fieldErrs, ok := err.(players.FieldErrors); ok {
+			tData.Error = l.errorf("some values are invalid, please correct them")
+			tData.Form = r.PostForm
+			tData.Errors = fieldErrs
+			tData.BlankRows = blankRows(r, len(base.Players), r.PostForm)
+		}
+		return l.execute(w, tmpl, tData)
+	}
+
+	g := *base
+	g.Revision = revision + 1
+	g.Players = p
+	g.Settings = settings
+	edit.Revision = g.Revision
+	edit.Time = time.Now().UTC().Truncate(time.Second)
+	edit.Editor = editor(r)
+	if edit.Changes == nil {
+		edit.Changes = append(players.Diff(base.Players, p), players.DiffSettings(base.Settings, settings)...)
+	}
+	g.Record(edit)
+	if g.ID == "" {
+		newGame, err := players.NewGame()
+		if err != nil {
+			tData.Error = l.error(err)
+			return l.execute(w, tmpl, tData)
+		}
+		g.ID = newGame.ID
+	}
+	if name := r.PostForm.Get("editor"); name != "" {
+		http.SetCookie(w, &http.Cookie{Name: "editor", Value: url.QueryEscape(name), Path: "/", MaxAge: 365 * 24 * 3600})
+	}
+	if latest, ok := games.commit(&g, revision); !ok {
+		theirs, err := latest.ToBase64()
+		if err != nil {
+			tData.Error = l.errorf("failed to encode players: %v", err)
+			return l.execute(w, tmpl, tData)
+		}
+		w.WriteHeader(http.StatusConflict)
+		return l.execute(w, mergeTmpl, mergeTmplData{
+			Rows:     mergeRows(base.Players, latest.Players, p, l.tag),
+			Theirs:   theirs,
+			Revision: latest.Revision,
+			Mine:     p,
+		})
+	}
+	data, err := g.ToBase64()
+	if err != nil {
+		tData.Error = l.errorf("failed to encode players: %v", err)
+		return l.execute(w, tmpl, tData)
+	}
+	w.Header().Set("Location", gameURL(r, data))
+	w.WriteHeader(http.StatusSeeOther)
+	return nil
+}
+
+// baseURL returns the absolute URL of the host the request was sent to.
+func baseURL(r *http.Request) url.URL {
 	scheme := "http"
 	if r.TLS != nil {
 		scheme = "https"
 	}
-	u := url.URL{
-		Scheme: scheme,
-		Host:   r.Host,
-		Path:   "/" + data,
+	return url.URL{Scheme: scheme, Host: r.Host}
+}
+
+// gameURL returns the absolute URL of the encoded game, on the host the
+// request was sent to.
+func gameURL(r *http.Request, data string) string {
+	u := baseURL(r)
+	u.Path = "/" + data
+	// Keep rounding the debts, and listing the players, the way the user
+	// chose to.
+	q := url.Values{}
+	for _, key := range []string{"unit", "sort"} {
+		if v := r.URL.Query().Get(key); v != "" {
+			q.Set(key, v)
+		}
 	}
-	w.Header().Set("Location", u.String())
-	w.WriteHeader(http.StatusSeeOther)
-	return nil
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+// reviseSettings returns the settings of the next revision of the game. The
+// standard buy-in is submitted along with the players, in the main form. If
+// it is invalid, the returned error is a players.FieldErrors.
+func reviseSettings(g *players.Game, form url.Values, l *locale) (*players.Settings, error) {
+	if _, ok := form["standardbuyin"]; !ok {
+		return g.Settings, nil
+	}
+	s, err := g.Settings.WithBuyIn(form.Get("standardbuyin"), l.tag)
+	if err != nil {
+		return nil, players.FieldErrors{"standardbuyin": err}
+	}
+	return s, nil
+}
+
+// revise returns the players and the settings of the next revision of the
+// game, according to the action requested in the form, along with the Edit
+// recording how the next revision relates to the previous ones. The other
+// fields of the Edit are left to the caller, except for the changes, which are
+// set when they can't be inferred by comparing the players and the settings.
+// If some fields of the form are invalid, the returned error is a
+// players.FieldErrors.
+func revise(g *players.Game, form url.Values, l *locale) (players.Players, *players.Settings, players.Edit, error) {
+	var p players.Players
+	settings := g.Settings
+	edit := players.Edit{Parent: g.Revision}
+	var err error
+	switch action := form.Get("action"); action {
+	case "":
+		p, err = players.FromForm(form, l.tag)
+		if _, ok := err.(players.FieldErrors); ok {
+			return nil, nil, players.Edit{}, err
+		}
+		if err != nil {
+			return nil, nil, players.Edit{}, l.errorf("failed to parse players from form: %v", err)
+		}
+		if names := players.SplitNames(form.Get("names")); len(names) > 0 {
+			if p, err = p.Add(names...); err != nil {
+				return nil, nil, players.Edit{}, players.FieldErrors{"names": err}
+			}
+		}
+		if settings, err = reviseSettings(g, form, l); err != nil {
+			return nil, nil, players.Edit{}, err
+		}
+	case "undo":
+		p, settings, edit, err = g.Undo()
+	case "redo":
+		p, settings, edit, err = g.Redo()
+	case "restore":
+		target, convErr := strconv.Atoi(form.Get("target"))
+		if convErr != nil {
+			return nil, nil, players.Edit{}, l.errorf("missing or invalid revision to restore: %v", convErr)
+		}
+		p, settings, edit, err = g.Restore(target)
+	case "rename":
+		var change players.Change
+		p, change, err = g.Players.Rename(form.Get("target"), form.Get("name"))
+		if err != nil {
+			return nil, nil, players.Edit{}, l.errorf("failed to rename player: %v", err)
+		}
+		edit.Changes = []players.Change{change}
+	case "remove":
+		p, err = g.Players.Remove(form.Get("target"))
+		if err != nil {
+			return nil, nil, players.Edit{}, l.errorf("failed to remove player: %v", err)
+		}
+	case "constrain":
+		p, err = g.Players.Constrain(form.Get("target"), form.Get("field"), form.Get("other"))
+		if err != nil {
+			return nil, nil, players.Edit{}, l.errorf("failed to add the constraint: %v", err)
+		}
+	case "unconstrain":
+		p, err = g.Players.Unconstrain(form.Get("target"), form.Get("field"), form.Get("other"))
+		if err != nil {
+			return nil, nil, players.Edit{}, l.errorf("failed to remove the constraint: %v", err)
+		}
+	case "rebuy":
+		if g.Settings == nil || g.Settings.BuyIn.IsZero() {
+			return nil, nil, players.Edit{}, l.errorf("the standard buy-in isn't set")
+		}
+		p, err = g.Players.Rebuy(form.Get("target"), g.Settings.BuyIn)
+		if err != nil {
+			return nil, nil, players.Edit{}, l.errorf("failed to rebuy: %v", err)
+		}
+	case "pay", "unpay":
+		if action == "pay" {
+			p, err = g.Players.Pay(form.Get("target"), form.Get("other"))
+		} else {
+			p, err = g.Players.Unpay(form.Get("target"), form.Get("other"))
+		}
+		if err != nil {
+			return nil, nil, players.Edit{}, l.errorf("failed to update the debt: %v", err)
+		}
+	default:
+		return nil, nil, players.Edit{}, l.errorf("unsupported action: %q", action)
+	}
+	if err != nil {
+		return nil, nil, players.Edit{}, l.error(err)
+	}
+	return p, settings, edit, nil
+}
+
+// editorName returns the name the user gave when they last edited a game, or
+// an empty string if unknown.
+func editorName(r *http.Request) string {
+	c, err := r.Cookie("editor")
+	if err != nil {
+		return ""
+	}
+	name, err := url.QueryUnescape(c.Value)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// editor identifies the person submitting the form: by the name they entered,
+// or last entered, or by their anonymized IP address if they never did. The
+// X-Forwarded-For header is only used if the request comes from a trusted
+// proxy, as anyone could forge it otherwise.
+func editor(r *http.Request) string {
+	if name := strings.TrimSpace(r.PostForm.Get("editor")); name != "" {
+		return name
+	}
+	if name := editorName(r); name != "" {
+		return name
+	}
+	return anonymizeIP(clientIP(r))
+}
+
+// clientIP returns the IP address of the client who sent the request. Behind
+// trusted proxies, it's the last address listed in the X-Forwarded-For header
+// which isn't the one of a trusted proxy.
+func clientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	fwd := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(fwd) - 1; i >= 0 && contains(TrustedProxies, ip); i-- {
+		if addr := strings.TrimSpace(fwd[i]); addr != "" {
+			ip = addr
+		}
+	}
+	return ip
+}
+
+// anonymizeIP returns the IP address with its last bits zeroed, so that the
+// URL of a game, which records its editors, doesn't identify their device:
+// the last 8 bits of an IPv4 address, and the last 80 bits of an IPv6 one.
+func anonymizeIP(addr string) string {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return addr
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
 }
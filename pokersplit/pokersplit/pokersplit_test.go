@@ -1,10 +1,16 @@
 package pokersplit
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/fhchstr/pokersplit/pokersplit/players"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"golang.org/x/text/language"
 )
 
 func TestSorted(t *testing.T) {
@@ -36,10 +42,372 @@ func TestSorted(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(c.desc, func(t *testing.T) {
-			got := sorted(c.input)
+			got := sorted(c.input, language.English)
 			if diff := cmp.Diff(c.want, got); diff != "" {
 				t.Errorf("sorted() mismatch (-want +got):\n%s", diff)
 			}
 		})
 	}
 }
+
+func TestNewMethods(t *testing.T) {
+	cases := []struct {
+		desc string
+		form url.Values
+		def  []string
+		want []methodOption
+	}{
+		{
+			desc: "default",
+			def:  []string{players.MethodMobile},
+			want: []methodOption{
+				{Name: "methods0", Method: players.MethodCash},
+				{Name: "methods0", Method: players.MethodMobile, Checked: true},
+			},
+		},
+		{
+			desc: "submitted",
+			form: url.Values{"methods0": []string{players.MethodCash}},
+			def:  []string{players.MethodMobile},
+			want: []methodOption{
+				{Name: "methods0", Method: players.MethodCash, Checked: true},
+				{Name: "methods0", Method: players.MethodMobile},
+			},
+		},
+		{
+			desc: "submitted_unchecked",
+			form: url.Values{"player0": []string{"alice"}},
+			def:  []string{players.MethodMobile},
+			want: []methodOption{
+				{Name: "methods0", Method: players.MethodCash},
+				{Name: "methods0", Method: players.MethodMobile},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			got := newMethods(c.form, "methods0", c.def)
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("newMethods() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSortBy(t *testing.T) {
+	p := players.Players{
+		{Name: "charlie", BuyIn: players.Cents(1000), Stack: players.Cents(1000)},
+		{Name: "bob", BuyIn: players.Cents(2000), Stack: players.Cents(500)},
+		{Name: "alice", BuyIn: players.Cents(1000), Stack: players.Cents(2500)},
+		{Name: "dan", BuyIn: players.Cents(1000), Stack: players.Cents(1000)},
+	}
+	cases := []struct {
+		order string
+		want  []string
+	}{
+		{order: "", want: []string{"alice", "bob", "charlie", "dan"}},
+		{order: "name", want: []string{"alice", "bob", "charlie", "dan"}},
+		{order: "net", want: []string{"alice", "charlie", "dan", "bob"}},
+		{order: "buyin", want: []string{"bob", "alice", "charlie", "dan"}},
+	}
+	for _, c := range cases {
+		var got []string
+		for _, player := range sortBy(p, c.order, language.English) {
+			got = append(got, player.Name)
+		}
+		if diff := cmp.Diff(c.want, got); diff != "" {
+			t.Errorf("sortBy(%q) mismatch (-want +got):\n%s", c.order, diff)
+		}
+	}
+}
+
+func TestExtremes(t *testing.T) {
+	p := players.Players{
+		{Name: "alice", BuyIn: players.Cents(1000), Stack: players.Cents(2000)},
+		{Name: "bob", BuyIn: players.Cents(1000), Stack: players.Cents(2000)},
+		{Name: "charlie", BuyIn: players.Cents(1000), Stack: players.Cents(500)},
+		{Name: "dan", BuyIn: players.Cents(2000), Stack: players.Cents(500)},
+		{Name: "eve", BuyIn: players.Cents(1000), Stack: players.Cents(1000)},
+	}
+	winners, loosers := extremes(p)
+	if diff := cmp.Diff(players.Players{p[0], p[1]}, winners); diff != "" {
+		t.Errorf("extremes() winners mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(players.Players{p[3]}, loosers); diff != "" {
+		t.Errorf("extremes() loosers mismatch (-want +got):\n%s", diff)
+	}
+
+	winners, loosers = extremes(players.Players{p[4]})
+	if len(winners) != 0 || len(loosers) != 0 {
+		t.Errorf("extremes() of players who broke even = %v, %v, want none", winners, loosers)
+	}
+}
+
+func TestTmplDataURL(t *testing.T) {
+	cases := []struct {
+		data  tmplData
+		key   string
+		value string
+		want  string
+	}{
+		{data: tmplData{}, key: "sort", value: "net", want: "?sort=net"},
+		{data: tmplData{Unit: "0.50", Sort: "buyin"}, key: "sort", value: "net", want: "?sort=net&unit=0.50"},
+		{data: tmplData{Unit: "0.50", Sort: "buyin"}, key: "sort", value: "", want: "?unit=0.50"},
+		{data: tmplData{Sort: "net"}, key: "sort", value: "", want: "?"},
+		{data: tmplData{Sort: "net", Rows: "12"}, key: "rows", value: "17", want: "?rows=17&sort=net"},
+	}
+	for _, c := range cases {
+		if got := c.data.URL(c.key, c.value); got != c.want {
+			t.Errorf("tmplData{Unit: %q, Sort: %q}.URL(%q, %q) = %q, want %q", c.data.Unit, c.data.Sort, c.key, c.value, got, c.want)
+		}
+	}
+}
+
+func TestBlankRows(t *testing.T) {
+	cases := []struct {
+		desc  string
+		query string
+		n     int
+		form  url.Values
+		want  []int
+	}{
+		{desc: "new_game", want: []int{0, 1, 2, 3, 4, 5, 6}},
+		{desc: "existing_game", n: 2, want: []int{2}},
+		{desc: "rows", query: "rows=3", n: 2, want: []int{2, 3, 4}},
+		{desc: "no_rows", query: "rows=0", n: 2},
+		{desc: "invalid_rows", query: "rows=-1", n: 2, want: []int{2}},
+		{desc: "max_rows", query: "rows=1000", n: 2, want: func() []int {
+			var ret []int
+			for i := 2; i < 2+maxRows; i++ {
+				ret = append(ret, i)
+			}
+			return ret
+		}()},
+		{desc: "submitted_rows", n: 2, form: url.Values{"player4": {"dave"}, "buyin9": {"10"}}, want: []int{2, 3, 4}},
+		{desc: "submitted_rows_without_rows", query: "rows=0", n: 2, form: url.Values{"player3": {"dave"}}, want: []int{2, 3}},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+c.query, nil)
+			if diff := cmp.Diff(c.want, blankRows(r, c.n, c.form)); diff != "" {
+				t.Errorf("blankRows() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// A negative default is clamped to zero.
+	defer func(rows int) { Rows = rows }(Rows)
+	Rows = -1
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if got := blankRows(r, 0, nil); len(got) != 0 {
+		t.Errorf("blankRows() with Rows = -1 returned %v, want no rows", got)
+	}
+}
+
+// savedGame decodes the game saved by update(), from the URL the response
+// redirects to.
+func savedGame(t *testing.T, w *httptest.ResponseRecorder) *players.Game {
+	t.Helper()
+	u, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid Location header: %v", err)
+	}
+	g, err := players.GameFromBase64(strings.TrimPrefix(u.Path, "/"))
+	if err != nil {
+		t.Fatalf("GameFromBase64() returned an error: %v", err)
+	}
+	return g
+}
+
+func TestUpdate(t *testing.T) {
+	cases := []struct {
+		desc string
+		game *players.Game
+		// latest is committed to the store before the form is posted, if set.
+		latest     *players.Game
+		form       url.Values
+		wantStatus int
+		// want are the players of the saved game, if it is saved.
+		want players.Players
+		// wantBody is part of the page displayed if the game isn't saved.
+		wantBody string
+	}{
+		{
+			desc:       "save",
+			game:       &players.Game{ID: "update-save", Revision: 1, Players: players.Players{{Name: "alice"}}},
+			form:       url.Values{"revision": {"1"}, "player0": {"alice"}, "buyin0": {"10"}},
+			wantStatus: http.StatusSeeOther,
+			want:       players.Players{{Name: "alice", BuyIn: players.Cents(1000)}},
+		},
+		{
+			desc:       "conflict",
+			game:       &players.Game{ID: "update-conflict", Revision: 1, Players: players.Players{{Name: "alice"}}},
+			latest:     &players.Game{ID: "update-conflict", Revision: 2, Players: players.Players{{Name: "alice", BuyIn: players.Cents(500)}}},
+			form:       url.Values{"revision": {"1"}, "player0": {"alice"}, "buyin0": {"10"}},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			desc:       "rename",
+			game:       &players.Game{ID: "update-rename", Revision: 1, Players: players.Players{{Name: "alcie", BuyIn: players.Cents(1000)}, {Name: "bob", Avoids: []string{"alcie"}}}},
+			form:       url.Values{"revision": {"1"}, "action": {"rename"}, "target": {"alcie"}, "name": {"alice"}},
+			wantStatus: http.StatusSeeOther,
+			want:       players.Players{{Name: "alice", BuyIn: players.Cents(1000)}, {Name: "bob", Avoids: []string{"alice"}}},
+		},
+		{
+			desc:       "rename_to_existing_name",
+			game:       &players.Game{ID: "update-rename-existing", Revision: 1, Players: players.Players{{Name: "alice"}, {Name: "bob"}}},
+			form:       url.Values{"revision": {"1"}, "action": {"rename"}, "target": {"bob"}, "name": {"Alice"}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			desc:       "remove",
+			game:       &players.Game{ID: "update-remove", Revision: 1, Players: players.Players{{Name: "alice"}, {Name: "bob", Prefers: []string{"alice"}}}},
+			form:       url.Values{"revision": {"1"}, "action": {"remove"}, "target": {"alice"}},
+			wantStatus: http.StatusSeeOther,
+			want:       players.Players{{Name: "bob"}},
+		},
+		{
+			// The duplicate name is reported on the new row, not on the one
+			// of the existing player.
+			desc:       "duplicate_name",
+			game:       &players.Game{ID: "update-duplicate", Revision: 1, Players: players.Players{{Name: "alice"}}},
+			form:       url.Values{"revision": {"1"}, "player0": {"alice"}, "player1": {"Alice"}},
+			wantStatus: http.StatusOK,
+			wantBody:   `<input id="player1" name="player1" type="text" value="Alice" class="is-invalid">`,
+		},
+		{
+			desc:       "bulk_add",
+			game:       &players.Game{ID: "update-bulk-add", Revision: 1, Players: players.Players{{Name: "alice"}}},
+			form:       url.Values{"revision": {"1"}, "player0": {"alice"}, "names": {"bob, charlie\ndave"}},
+			wantStatus: http.StatusSeeOther,
+			want:       players.Players{{Name: "alice"}, {Name: "bob"}, {Name: "charlie"}, {Name: "dave"}},
+		},
+		{
+			desc:       "bulk_add_existing_name",
+			game:       &players.Game{ID: "update-bulk-add-existing", Revision: 1, Players: players.Players{{Name: "alice"}}},
+			form:       url.Values{"revision": {"1"}, "player0": {"alice"}, "names": {"bob; Alice"}},
+			wantStatus: http.StatusOK,
+			wantBody:   `<textarea id="names" name="names" rows="3" class="form-control is-invalid">bob; Alice</textarea>`,
+		},
+		{
+			desc:       "rebuy",
+			game:       &players.Game{ID: "update-rebuy", Revision: 1, Players: players.Players{{Name: "alice", BuyIn: players.Cents(2000)}}, Settings: &players.Settings{BuyIn: players.Cents(2000)}},
+			form:       url.Values{"revision": {"1"}, "action": {"rebuy"}, "target": {"alice"}},
+			wantStatus: http.StatusSeeOther,
+			want:       players.Players{{Name: "alice", BuyIn: players.Cents(4000)}},
+		},
+		{
+			desc:       "rebuy_without_standard_buy_in",
+			game:       &players.Game{ID: "update-rebuy-unset", Revision: 1, Players: players.Players{{Name: "alice", BuyIn: players.Cents(2000)}}},
+			form:       url.Values{"revision": {"1"}, "action": {"rebuy"}, "target": {"alice"}},
+			wantStatus: http.StatusOK,
+			wantBody:   "the standard buy-in isn&#39;t set",
+		},
+		{
+			desc:       "stale_revision",
+			game:       &players.Game{ID: "update-stale", Revision: 2, Players: players.Players{{Name: "alice"}}},
+			form:       url.Values{"revision": {"1"}, "player0": {"alice"}},
+			wantStatus: http.StatusOK,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			if c.latest != nil {
+				games.commit(c.latest, 0)
+			}
+			data, err := c.game.ToBase64()
+			if err != nil {
+				t.Fatalf("ToBase64() returned an error: %v", err)
+			}
+			r := httptest.NewRequest(http.MethodPost, "/"+data, strings.NewReader(c.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			ServeHTTP(w, r)
+			if w.Code != c.wantStatus {
+				t.Fatalf("ServeHTTP() status = %d, want %d:\n%s", w.Code, c.wantStatus, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), c.wantBody) {
+				t.Errorf("ServeHTTP() body doesn't contain %q:\n%s", c.wantBody, w.Body.String())
+			}
+			if c.wantStatus != http.StatusSeeOther {
+				return
+			}
+			got := savedGame(t, w)
+			if got.Revision != c.game.Revision+1 {
+				t.Errorf("ServeHTTP() saved revision %d, want %d", got.Revision, c.game.Revision+1)
+			}
+			if diff := cmp.Diff(c.want, got.Players, cmpopts.EquateEmpty()); diff != "" {
+				t.Errorf("ServeHTTP() players mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEditor(t *testing.T) {
+	defer func(proxies []string) { TrustedProxies = proxies }(TrustedProxies)
+	TrustedProxies = []string{"10.0.0.1"}
+	cases := []struct {
+		desc       string
+		form       url.Values
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{desc: "name", form: url.Values{"editor": {" alice "}}, remoteAddr: "192.0.2.7:1234", want: "alice"},
+		{desc: "ip", remoteAddr: "192.0.2.7:1234", want: "192.0.2.0"},
+		{desc: "ipv6", remoteAddr: "[2001:db8:1:2::7]:1234", want: "2001:db8:1::"},
+		// Only trusted proxies can tell the IP address of the client.
+		{desc: "forged_forwarded_for", remoteAddr: "192.0.2.7:1234", forwarded: "198.51.100.9", want: "192.0.2.0"},
+		{desc: "trusted_proxy", remoteAddr: "10.0.0.1:1234", forwarded: "203.0.113.5, 198.51.100.9", want: "198.51.100.0"},
+		{desc: "trusted_proxies", remoteAddr: "10.0.0.1:1234", forwarded: "198.51.100.9, 10.0.0.1", want: "198.51.100.0"},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(c.form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			r.RemoteAddr = c.remoteAddr
+			if c.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", c.forwarded)
+			}
+			r.ParseForm()
+			if got := editor(r); got != c.want {
+				t.Errorf("editor() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestShowPayForm(t *testing.T) {
+	g := &players.Game{Players: players.Players{
+		{Name: "a", BuyIn: players.Cents(40), Stack: players.Cents(120)},
+		{Name: "b", BuyIn: players.Cents(290), Stack: players.Cents(210)},
+		{Name: "c", BuyIn: players.Cents(130), Stack: players.Cents(30)},
+		{Name: "d", BuyIn: players.Cents(210), Stack: players.Cents(310)},
+	}}
+	data, err := g.ToBase64()
+	if err != nil {
+		t.Fatalf("ToBase64() returned an error: %v", err)
+	}
+	cases := []struct {
+		desc     string
+		query    string
+		wantForm bool
+	}{
+		{desc: "exact_amounts", wantForm: true},
+		// The rounded debts may not match the debts which can be paid.
+		{desc: "rounded_amounts", query: "?unit=1.00", wantForm: false},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/"+data+c.query, nil))
+			body := w.Body.String()
+			if got := strings.Contains(body, `name="action" value="pay"`); got != c.wantForm {
+				t.Errorf("ServeHTTP() body contains the form to mark debts as paid = %t, want %t", got, c.wantForm)
+			}
+			if got := strings.Contains(body, "Show the exact amounts to mark debts as paid."); got == c.wantForm {
+				t.Errorf("ServeHTTP() body contains the hint to show the exact amounts = %t, want %t", got, !c.wantForm)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package pokersplit
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed manifest.webmanifest
+var manifest string
+
+//go:embed sw.js
+var serviceWorkerTmpl string
+
+// serviceWorker is the service worker, which caches the static assets by
+// their current path.
+var serviceWorker = renderServiceWorker()
+
+//go:embed icon.svg
+var icon string
+
+// pwaFile is a file making PokerSplit a Progressive Web App.
+type pwaFile struct {
+	contentType string
+	content     string
+}
+
+// pwaFiles maps the paths of the files making PokerSplit a Progressive Web App
+// to their content. The service worker must be served from the root to
+// control all the pages.
+var pwaFiles = map[string]pwaFile{
+	"/manifest.webmanifest": {"application/manifest+json", manifest},
+	"/sw.js":                {"text/javascript; charset=utf-8", serviceWorker},
+	"/icon.svg":             {"image/svg+xml", icon},
+}
+
+// ServePWA serves the files making PokerSplit a Progressive Web App, which
+// can be installed and used offline: its web manifest, its icon, its service
+// worker. The script calculating the debts on the device when offline is a
+// static asset.
+func ServePWA(w http.ResponseWriter, r *http.Request) {
+	f, ok := pwaFiles[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", f.contentType)
+	// Have the browser check for updates every time, so that the service
+	// worker and the script don't get out of sync with the pages.
+	w.Header().Set("Cache-Control", "no-cache")
+	io.WriteString(w, f.content)
+}
+
+// renderServiceWorker returns the service worker, with the paths of the
+// static assets to cache. Its cache is named after them, so that a new version
+// of the assets replaces the cached one.
+func renderServiceWorker() string {
+	var paths []string
+	for _, a := range assets {
+		paths = append(paths, a.path)
+	}
+	sort.Strings(paths)
+	hash := sha256.Sum256([]byte(strings.Join(paths, "\n")))
+	var b strings.Builder
+	t := template.Must(template.New("sw").Parse(serviceWorkerTmpl))
+	if err := t.Execute(&b, struct {
+		Version string
+		Assets  []string
+	}{hex.EncodeToString(hash[:5]), paths}); err != nil {
+		panic(err)
+	}
+	return b.String()
+}
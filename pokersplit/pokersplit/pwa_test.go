@@ -0,0 +1,33 @@
+package pokersplit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestServePWA(t *testing.T) {
+	for path, want := range map[string]string{
+		"/manifest.webmanifest": "application/manifest+json",
+		"/sw.js":                "text/javascript; charset=utf-8",
+		"/icon.svg":             "image/svg+xml",
+	} {
+		w := httptest.NewRecorder()
+		ServePWA(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("ServePWA(%q) status = %d, want %d", path, w.Code, http.StatusOK)
+		}
+		if got := w.Header().Get("Content-Type"); got != want {
+			t.Errorf("ServePWA(%q) Content-Type = %q, want %q", path, got, want)
+		}
+		if w.Body.Len() == 0 {
+			t.Errorf("ServePWA(%q) body is empty", path)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	ServePWA(w, httptest.NewRequest(http.MethodGet, "/unknown.js", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("ServePWA() of an unknown file status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
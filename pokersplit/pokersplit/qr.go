@@ -0,0 +1,174 @@
+package pokersplit
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+	"rsc.io/qr"
+)
+
+// quietZone is the width of the white border around the QR codes, in
+// modules, as required by the QR code specification.
+const quietZone = 4
+
+// maxQRText is the length of the longest text which can be encoded in a QR
+// code, with the lowest error correction level.
+const maxQRText = 2953
+
+// qrSVG encodes the text in a QR code with the given error correction level,
+// and renders it as an SVG image. If swissCross is true, the Swiss cross
+// required by Swiss QR-bills is drawn in the middle of the code.
+func qrSVG(text string, level qr.Level, swissCross bool) ([]byte, error) {
+	code, err := qr.Encode(text, level)
+	if err != nil {
+		return nil, err
+	}
+	size := code.Size + 2*quietZone
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, size, size)
+	for y := 0; y < code.Size; y++ {
+		for x := 0; x < code.Size; x++ {
+			if code.Black(x, y) {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x+quietZone, y+quietZone)
+			}
+		}
+	}
+	buf.WriteString(`"/>`)
+	if swissCross {
+		// The cross measures 7mm in a code measuring 46mm. It is a black
+		// square with a white border, containing a white cross whose
+		// proportions are those of the Swiss flag.
+		c := float64(size) / 2
+		outer := float64(code.Size) * 7 / 46
+		inner := outer * 6 / 7
+		arm, width := inner*20/32, inner*6/32
+		fmt.Fprintf(&buf, `<rect x="%.3f" y="%.3f" width="%.3f" height="%.3f" fill="#fff"/>`, c-outer/2, c-outer/2, outer, outer)
+		fmt.Fprintf(&buf, `<rect x="%.3f" y="%.3f" width="%.3f" height="%.3f" fill="#000"/>`, c-inner/2, c-inner/2, inner, inner)
+		fmt.Fprintf(&buf, `<rect x="%.3f" y="%.3f" width="%.3f" height="%.3f" fill="#fff"/>`, c-width/2, c-arm/2, width, arm)
+		fmt.Fprintf(&buf, `<rect x="%.3f" y="%.3f" width="%.3f" height="%.3f" fill="#fff"/>`, c-arm/2, c-width/2, arm, width)
+	}
+	buf.WriteString(`</svg>`)
+	return buf.Bytes(), nil
+}
+
+// dataURL returns the SVG image as a data URL, to embed it in a page.
+func dataURL(svg []byte) template.URL {
+	return template.URL("data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(svg))
+}
+
+// payment holds the QR code a debtor can scan to pay a debt.
+type payment struct {
+	// QR is the QR code as a data URL, or empty if it couldn't be generated.
+	QR template.URL
+	// Error is the reason why the QR code couldn't be generated, if any.
+	Error string
+}
+
+// payments returns the payments of the debts, in the currency of the game,
+// which may be empty, in the same order. The QR codes are only generated for
+// the creditors who provided their IBAN.
+func payments(p players.Players, debts players.Debts, currency string, l *locale) map[string][]payment {
+	creditors := make(map[string]*players.Player)
+	for _, player := range p {
+		creditors[player.Name] = player
+	}
+	ret := make(map[string][]payment)
+	for debtor, ds := range debts {
+		for _, d := range ds {
+			var pay payment
+			if creditor := creditors[d.Creditor]; creditor != nil && creditor.IBAN != "" {
+				svg, err := paymentQR(debtor, creditor, d.Amount, currency)
+				if err != nil {
+					pay.Error = l.translate(err)
+				} else {
+					pay.QR = dataURL(svg)
+				}
+			}
+			ret[debtor] = append(ret[debtor], pay)
+		}
+	}
+	return ret
+}
+
+// paymentQR returns the QR code of the payment request of the debt, as an SVG
+// image.
+func paymentQR(debtor string, creditor *players.Player, amount players.Money, currency string) ([]byte, error) {
+	req, err := players.NewPaymentRequest(debtor, creditor, amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	return qrSVG(req.Payload, qr.M, req.Format == players.FormatQRBill)
+}
+
+// shareURL returns the URL to share the game encoded in data. If the game is
+// in the store, it is the short URL resolving to its latest revision, else the
+// URL of the game itself. The "unit" and "sort" URL parameters are kept.
+func shareURL(r *http.Request, g *players.Game, data string) string {
+	if g.ID != "" {
+		if _, ok := games.get(g.ID); ok {
+			return gameURL(r, "g/"+g.ID)
+		}
+	}
+	return gameURL(r, data)
+}
+
+// ServeQR renders the share URL of the game encoded in the URL path, after the
+// "/qr/" prefix, as a QR code, so that the other players can scan it to open
+// the game. The error correction level is lowered if the URL is too long for
+// the default one.
+func ServeQR(w http.ResponseWriter, r *http.Request) {
+	l := negotiate(w, r)
+	if r.Method != http.MethodGet {
+		http.Error(w, l.errorf("unsupported HTTP method: %s", r.Method).Error(), http.StatusMethodNotAllowed)
+		return
+	}
+	data := strings.TrimPrefix(r.URL.Path, "/qr/")
+	g, err := players.GameFromBase64(data)
+	if err != nil {
+		http.Error(w, l.errorf("failed to decode players: %v", err).Error(), http.StatusBadRequest)
+		return
+	}
+	u := shareURL(r, g, data)
+	if len(u) > maxQRText {
+		http.Error(w, l.errorf("the link to the game is too long to be encoded in a QR code").Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	svg, err := qrSVG(u, qr.M, false)
+	if err != nil {
+		svg, err = qrSVG(u, qr.L, false)
+	}
+	if err != nil {
+		http.Error(w, l.errorf("failed to generate the QR code: %v", err).Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/svg+xml")
+	w.Write(svg)
+}
+
+// ServeShortURL redirects to the latest revision of the game whose ID follows
+// the "/g/" prefix in the URL path. Only the games in the store can be found.
+func ServeShortURL(w http.ResponseWriter, r *http.Request) {
+	l := negotiate(w, r)
+	if r.Method != http.MethodGet {
+		http.Error(w, l.errorf("unsupported HTTP method: %s", r.Method).Error(), http.StatusMethodNotAllowed)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/g/")
+	g, ok := games.get(id)
+	if !ok {
+		http.Error(w, l.errorf("game %q not found, it may have been forgotten since it was shared", id).Error(), http.StatusNotFound)
+		return
+	}
+	data, err := g.ToBase64()
+	if err != nil {
+		http.Error(w, l.errorf("failed to encode players: %v", err).Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, gameURL(r, data), http.StatusFound)
+}
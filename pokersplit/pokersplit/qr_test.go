@@ -0,0 +1,203 @@
+package pokersplit
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+	"golang.org/x/text/language"
+	"rsc.io/qr"
+)
+
+func TestQRSVG(t *testing.T) {
+	cases := []struct {
+		swissCross bool
+		// wantRects is the number of rectangles: the background, and the
+		// Swiss cross made of a white and a black square and a white cross.
+		wantRects int
+	}{
+		{swissCross: false, wantRects: 1},
+		{swissCross: true, wantRects: 5},
+	}
+	for _, c := range cases {
+		svg, err := qrSVG("https://example.com/", qr.M, c.swissCross)
+		if err != nil {
+			t.Fatalf("qrSVG() returned an error: %v", err)
+		}
+		// A version 2 code has 25 modules, plus the quiet zone.
+		if want := `viewBox="0 0 33 33"`; !strings.Contains(string(svg), want) {
+			t.Errorf("qrSVG(%t) = %s, want it to contain %s", c.swissCross, svg, want)
+		}
+		if got := strings.Count(string(svg), "<rect"); got != c.wantRects {
+			t.Errorf("qrSVG(%t) has %d rectangles, want %d", c.swissCross, got, c.wantRects)
+		}
+	}
+}
+
+func TestPayments(t *testing.T) {
+	p := players.Players{
+		{Name: "alice", IBAN: "DE89370400440532013000"},
+		{Name: "bob", IBAN: "CH9300762011623852957"},
+		{Name: "charlie"},
+		{Name: "dave"},
+	}
+	debts := players.Debts{
+		"dave": {
+			{Creditor: "alice", Amount: players.Cents(1000)},
+			{Creditor: "bob", Amount: players.Cents(500)},
+			{Creditor: "charlie", Amount: players.Cents(200)},
+		},
+	}
+	got := payments(p, debts, "", newLocale(language.French))["dave"]
+	if len(got) != 3 {
+		t.Fatalf("payments() returned %d payments, want 3", len(got))
+	}
+	if !strings.HasPrefix(string(got[0].QR), "data:image/svg+xml;base64,") || got[0].Error != "" {
+		t.Errorf("payments()[0] = %+v, want a QR code", got[0])
+	}
+	if want := `le NPA et la localité de "bob" sont nécessaires pour une QR-facture suisse`; got[1].QR != "" || got[1].Error != want {
+		t.Errorf("payments()[1] = %+v, want error %q", got[1], want)
+	}
+	if got[2] != (payment{}) {
+		t.Errorf("payments()[2] = %+v, want no payment", got[2])
+	}
+
+	// The EPC QR codes can't request payments in CHF.
+	got = payments(p, debts, "CHF", newLocale(language.French))["dave"]
+	if want := `l'IBAN de "alice" n'accepte que les demandes de paiement en EUR, pas CHF`; got[0].QR != "" || got[0].Error != want {
+		t.Errorf("payments()[0] = %+v, want error %q", got[0], want)
+	}
+}
+
+// longGame returns a game whose URL is too long to be encoded in a QR code.
+// The names of its players are random, so that they can't be compressed.
+func longGame(t *testing.T, id string) (*players.Game, string) {
+	t.Helper()
+	rnd := rand.New(rand.NewSource(1))
+	g := &players.Game{ID: id}
+	for i := 0; i < 400; i++ {
+		name := make([]byte, 16)
+		for j := range name {
+			name[j] = byte('a' + rnd.Intn(26))
+		}
+		g.Players = append(g.Players, &players.Player{Name: fmt.Sprintf("%s%d", name, i)})
+	}
+	data, err := g.ToBase64()
+	if err != nil {
+		t.Fatalf("ToBase64() returned an error: %v", err)
+	}
+	if len(data) <= maxQRText {
+		t.Fatalf("the long game is encoded in %d bytes, want more than %d", len(data), maxQRText)
+	}
+	return g, data
+}
+
+func TestShareURL(t *testing.T) {
+	saved, savedData := longGame(t, "shareurl-saved")
+	games.commit(saved, saved.Revision)
+	unsaved, unsavedData := longGame(t, "shareurl-unsaved")
+	cases := []struct {
+		desc string
+		g    *players.Game
+		data string
+		want string
+	}{
+		{desc: "saved", g: saved, data: savedData, want: "http://example.com/g/shareurl-saved?unit=0.5"},
+		{desc: "unsaved", g: unsaved, data: unsavedData, want: "http://example.com/" + unsavedData + "?unit=0.5"},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/qr/"+c.data+"?unit=0.5", nil)
+			if got := shareURL(r, c.g, c.data); got != c.want {
+				t.Errorf("shareURL() = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestServeQR(t *testing.T) {
+	g := &players.Game{Players: players.Players{{Name: "alice"}}}
+	data, err := g.ToBase64()
+	if err != nil {
+		t.Fatalf("ToBase64() returned an error: %v", err)
+	}
+	saved, savedData := longGame(t, "serveqr-saved")
+	games.commit(saved, saved.Revision)
+	_, unsavedData := longGame(t, "serveqr-unsaved")
+	cases := []struct {
+		desc       string
+		url        string
+		wantStatus int
+		wantBody   string
+	}{
+		{desc: "game", url: "/qr/" + data + "?unit=0.5", wantStatus: http.StatusOK},
+		{desc: "invalid_game", url: "/qr/abc", wantStatus: http.StatusBadRequest},
+		{desc: "long_saved_game", url: "/qr/" + savedData, wantStatus: http.StatusOK},
+		{desc: "long_unsaved_game", url: "/qr/" + unsavedData, wantStatus: http.StatusRequestEntityTooLarge, wantBody: "the link to the game is too long to be encoded in a QR code"},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ServeQR(w, httptest.NewRequest(http.MethodGet, c.url, nil))
+			if w.Code != c.wantStatus {
+				t.Fatalf("ServeQR() status = %d, want %d", w.Code, c.wantStatus)
+			}
+			if !strings.Contains(w.Body.String(), c.wantBody) {
+				t.Errorf("ServeQR() body = %q, want it to contain %q", w.Body, c.wantBody)
+			}
+			if got := w.Header().Get("Content-Type"); c.wantStatus == http.StatusOK && got != "image/svg+xml" {
+				t.Errorf("ServeQR() Content-Type = %q, want image/svg+xml", got)
+			}
+		})
+	}
+}
+
+func TestServeShortURL(t *testing.T) {
+	g := &players.Game{ID: "serveshorturl", Revision: 3, Players: players.Players{{Name: "alice"}}}
+	games.commit(g, g.Revision)
+	data, err := g.ToBase64()
+	if err != nil {
+		t.Fatalf("ToBase64() returned an error: %v", err)
+	}
+	cases := []struct {
+		desc         string
+		url          string
+		wantStatus   int
+		wantLocation string
+	}{
+		{desc: "saved_game", url: "/g/serveshorturl?unit=0.5", wantStatus: http.StatusFound, wantLocation: "http://example.com/" + data + "?unit=0.5"},
+		{desc: "unknown_game", url: "/g/unknown", wantStatus: http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			ServeShortURL(w, httptest.NewRequest(http.MethodGet, c.url, nil))
+			if w.Code != c.wantStatus {
+				t.Fatalf("ServeShortURL() status = %d, want %d", w.Code, c.wantStatus)
+			}
+			if got := w.Header().Get("Location"); got != c.wantLocation {
+				t.Errorf("ServeShortURL() Location = %q, want %q", got, c.wantLocation)
+			}
+		})
+	}
+}
+
+func TestShowLongGame(t *testing.T) {
+	_, data := longGame(t, "showlonggame")
+	w := httptest.NewRecorder()
+	ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/"+data, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("ServeHTTP() status = %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	if want := "The link to this game is too long to be encoded in a QR code."; !strings.Contains(body, want) {
+		t.Errorf("ServeHTTP() body doesn't contain %q", want)
+	}
+	if strings.Contains(body, `<img src="/qr/`) {
+		t.Errorf("ServeHTTP() body contains the QR code of the game")
+	}
+}
@@ -0,0 +1,123 @@
+package pokersplit
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+)
+
+// Layout of the report, in points.
+const (
+	reportMargin  = 50
+	reportRow     = 18
+	reportColumn1 = 330 // Right edge of the buy-ins.
+	reportColumn2 = 430 // Right edge of the stacks.
+	reportColumn3 = pageWidth - reportMargin
+)
+
+// writeReport writes a printable report of the finished game in PDF: the
+// buy-in, stack and net result of each player, followed by the transfers
+// settling the debts.
+func writeReport(w io.Writer, p players.Players, s *players.Settlement, l *locale, now time.Time) error {
+	var d pdf
+	y := float64(reportMargin + 10)
+	// newline moves to the next line, starting a new page if needed.
+	newline := func() {
+		y += reportRow
+		if y > pageHeight-reportMargin {
+			d.addPage()
+			y = reportMargin + 10
+		}
+	}
+	row := func(bold bool, name, buyIn, stack, net string) {
+		d.text(reportMargin, y, 11, bold, name)
+		d.textRight(reportColumn1, y, 11, bold, buyIn)
+		d.textRight(reportColumn2, y, 11, bold, stack)
+		d.textRight(reportColumn3, y, 11, bold, net)
+	}
+
+	d.text(reportMargin, y, 18, true, l.p.Sprintf("PokerSplit Game Report"))
+	newline()
+	d.text(reportMargin, y, 10, false, l.p.Sprintf("Generated on %s", now.UTC().Format("2006-01-02")))
+	newline()
+	newline()
+	row(true, l.p.Sprintf("Player"), l.p.Sprintf("Buy-In"), l.p.Sprintf("Stack"), l.p.Sprintf("Net"))
+	d.line(reportMargin, y+5, reportColumn3, y+5)
+	for _, player := range sorted(p, l.tag) {
+		newline()
+		row(false, player.Name, l.amount(player.BuyIn), l.amount(player.Stack), l.signed(player.Net()))
+	}
+	d.line(reportMargin, y+5, reportColumn3, y+5)
+	newline()
+	row(true, l.p.Sprintf("Total"), l.amount(p.BuyIn()), l.amount(p.Stack()), "")
+
+	newline()
+	newline()
+	d.text(reportMargin, y, 14, true, l.p.Sprintf("Transfers"))
+	if len(s.Debts) == 0 {
+		newline()
+		d.text(reportMargin, y, 11, false, l.p.Sprintf("Nobody owes anything."))
+	}
+	for _, debtor := range s.Debts.Debtors(l.tag) {
+		for _, debt := range s.Debts[debtor] {
+			newline()
+			text := l.p.Sprintf("%s owes %s to %s", debtor, l.amount(debt.Amount), debt.Creditor)
+			if debt.Paid {
+				text += " " + l.p.Sprintf("(paid)")
+			}
+			d.text(reportMargin, y, 11, false, text)
+		}
+	}
+	if len(s.Adjustments) > 0 {
+		newline()
+		newline()
+		d.text(reportMargin, y, 11, false, l.p.Sprintf("To round the transfers, the following balances were adjusted:"))
+		for _, player := range sorted(adjusted(s.Adjustments), l.tag) {
+			newline()
+			a := s.Adjustments[player.Name]
+			text := l.p.Sprintf("%s gets %s more", player.Name, l.amount(a))
+			if a.Sign() < 0 {
+				text = l.p.Sprintf("%s gets %s less", player.Name, l.amount(a.Neg()))
+			}
+			d.text(reportMargin+10, y, 11, false, "• "+text)
+		}
+	}
+	return d.writeTo(w)
+}
+
+// adjusted returns the players whose balance was adjusted, so that they can
+// be sorted.
+func adjusted(adjustments map[string]players.Money) players.Players {
+	var ret players.Players
+	for name := range adjustments {
+		ret = append(ret, &players.Player{Name: name})
+	}
+	return ret
+}
+
+// ServeReport writes a printable PDF report of the game encoded in the URL
+// path, after the "/report/" prefix. The debts are rounded according to the
+// "unit" URL parameter, like on the game's page.
+func ServeReport(w http.ResponseWriter, r *http.Request) {
+	l := negotiate(w, r)
+	if r.Method != http.MethodGet {
+		http.Error(w, l.errorf("unsupported HTTP method: %s", r.Method).Error(), http.StatusMethodNotAllowed)
+		return
+	}
+	g, err := players.GameFromBase64(strings.TrimPrefix(r.URL.Path, "/report/"))
+	if err != nil {
+		http.Error(w, l.errorf("failed to decode players: %v", err).Error(), http.StatusBadRequest)
+		return
+	}
+	s, err := settle(g.Players, r, l)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="pokersplit-report.pdf"`)
+	writeReport(w, g.Players, s, l, time.Now())
+}
@@ -0,0 +1,80 @@
+package pokersplit
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+	"golang.org/x/text/language"
+)
+
+func TestWriteReport(t *testing.T) {
+	p := players.Players{
+		{Name: "alice", BuyIn: players.Cents(1000), Stack: players.Cents(2000)},
+		{Name: "bob", BuyIn: players.Cents(1000), Stack: players.Cents(0)},
+	}
+	s, err := p.Settle(players.SettleOptions{})
+	if err != nil {
+		t.Fatalf("Players.Settle() returned an error: %v", err)
+	}
+	var buf bytes.Buffer
+	now := time.Date(2021, 6, 1, 20, 0, 0, 0, time.UTC)
+	if err := writeReport(&buf, p, s, newLocale(language.French), now); err != nil {
+		t.Fatalf("writeReport() returned an error: %v", err)
+	}
+	for _, want := range []string{
+		"(Rapport de partie PokerSplit)",
+		"(G\xe9n\xe9r\xe9 le 2021-06-01)",
+		"(alice)",
+		"(+10,00)",
+		"(-10,00)",
+		"(bob doit 10,00 \xe0 alice)",
+		"/Count 1",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeReport() = %q, want it to contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestWriteReportPages(t *testing.T) {
+	var p players.Players
+	for i := 0; i < 60; i++ {
+		p = append(p, &players.Player{Name: fmt.Sprintf("player %d", i)})
+	}
+	var buf bytes.Buffer
+	if err := writeReport(&buf, p, &players.Settlement{}, newLocale(language.English), time.Now()); err != nil {
+		t.Fatalf("writeReport() returned an error: %v", err)
+	}
+	if want := "/Count 2"; !strings.Contains(buf.String(), want) {
+		t.Errorf("writeReport() of 60 players doesn't contain %q", want)
+	}
+}
+
+func TestServeReport(t *testing.T) {
+	g := &players.Game{Players: players.Players{{Name: "alice", BuyIn: players.Cents(1000)}}}
+	data, err := g.ToBase64()
+	if err != nil {
+		t.Fatalf("ToBase64() returned an error: %v", err)
+	}
+	w := httptest.NewRecorder()
+	ServeReport(w, httptest.NewRequest(http.MethodGet, "/report/"+data, nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("ServeReport() of an unfinished game status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	g.Players[0].Stack = players.Cents(1000)
+	if data, err = g.ToBase64(); err != nil {
+		t.Fatalf("ToBase64() returned an error: %v", err)
+	}
+	w = httptest.NewRecorder()
+	ServeReport(w, httptest.NewRequest(http.MethodGet, "/report/"+data, nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" {
+		t.Errorf("ServeReport() status = %d, Content-Type = %q, want a PDF", w.Code, w.Header().Get("Content-Type"))
+	}
+}
@@ -0,0 +1,87 @@
+package pokersplit
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+)
+
+//go:embed static
+var staticFS embed.FS
+
+// staticTypes maps the extensions of the static assets to their content type.
+var staticTypes = map[string]string{
+	".css": "text/css; charset=utf-8",
+	".js":  "text/javascript; charset=utf-8",
+}
+
+// asset is a static asset, served under a path containing the hash of its
+// content, so that it can be cached forever.
+type asset struct {
+	path        string
+	contentType string
+	content     []byte
+}
+
+// assets maps the names of the static assets to the asset, and assetsByPath
+// maps their path to the asset.
+var assets, assetsByPath = loadAssets()
+
+// loadAssets reads the static assets embedded in the binary.
+func loadAssets() (map[string]*asset, map[string]*asset) {
+	byName := make(map[string]*asset)
+	byPath := make(map[string]*asset)
+	err := fs.WalkDir(staticFS, "static", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := staticFS.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimPrefix(p, "static/")
+		ext := path.Ext(name)
+		hash := sha256.Sum256(content)
+		a := &asset{
+			path:        "/static/" + strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(hash[:5]) + ext,
+			contentType: staticTypes[ext],
+			content:     content,
+		}
+		byName[name] = a
+		byPath[a.path] = a
+		return nil
+	})
+	if err != nil {
+		panic(fmt.Sprintf("failed to load the static assets: %v", err))
+	}
+	return byName, byPath
+}
+
+// staticPath returns the path of the static asset having the given name,
+// e.g. "/static/pokersplit.0123456789.css" for "pokersplit.css".
+func staticPath(name string) (string, error) {
+	a, ok := assets[name]
+	if !ok {
+		return "", fmt.Errorf("unknown static asset %q", name)
+	}
+	return a.path, nil
+}
+
+// ServeStatic serves the static assets: the style sheet of the pages and
+// their scripts. Their path contains the hash of their content, so they can
+// be cached forever, and don't have to be loaded from a CDN.
+func ServeStatic(w http.ResponseWriter, r *http.Request) {
+	a, ok := assetsByPath[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", a.contentType)
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Write(a.content)
+}
@@ -0,0 +1,306 @@
+// offline.js keeps PokerSplit usable without network access. It registers the
+// service worker caching the pages, and submits the players' form in the
+// background. If the server can't be reached, the form is kept on the device
+// and submitted once the network is back, which saves the game in its URL as
+// usual. Meanwhile, the debts are calculated on the device, using the same
+// algorithm as the server: see Players.Settle() in the players package.
+"use strict";
+
+(() => {
+  if ("serviceWorker" in navigator) {
+    navigator.serviceWorker.register("/sw.js");
+  }
+  const form = document.getElementById("players");
+  const status = document.getElementById("offline");
+  if (!form || !status) {
+    return;
+  }
+  const pendingKey = "pokersplit:pending:" + location.pathname;
+  const lang = document.documentElement.lang;
+  const decimalSep = new Intl.NumberFormat(lang).format(0.5).replace(/[05]/g, "");
+  const amountFormat = new Intl.NumberFormat(lang, {minimumFractionDigits: 2, maximumFractionDigits: 4});
+  const collator = new Intl.Collator("en", {sensitivity: "base"});
+
+  // Amounts are integers of ten-thousandths of a currency unit, the largest
+  // precision accepted in the form, so that they are exact.
+  const scale = 10000;
+
+  // submit posts the form's data to the game's URL and displays the response,
+  // like the browser does. It fails if the server can't be reached.
+  const submit = (body) =>
+    fetch(location.href, {
+      method: "POST",
+      headers: {"Content-Type": "application/x-www-form-urlencoded"},
+      body: body,
+    }).then((response) => {
+      localStorage.removeItem(pendingKey);
+      if (response.redirected) {
+        location.assign(response.url);
+        return;
+      }
+      return response.text().then((html) => {
+        document.open();
+        document.write(html);
+        document.close();
+      });
+    });
+
+  // sync submits the form kept on the device, if any.
+  const sync = () => {
+    const body = localStorage.getItem(pendingKey);
+    if (body !== null) {
+      submit(body).catch(() => showOffline(body));
+    }
+  };
+
+  // message returns the translated message of the status element, with its
+  // placeholders replaced, e.g. "{debtor}".
+  const message = (name, args) =>
+    Object.keys(args || {}).reduce((msg, key) => msg.replace("{" + key + "}", args[key]), status.dataset[name]);
+
+  // showOffline displays the debts of the players submitted in the form.
+  const showOffline = (body) => {
+    status.hidden = false;
+    const result = status.querySelector("[data-result]");
+    result.textContent = "";
+    const line = (text, tag) => {
+      const el = document.createElement(tag || "div");
+      el.textContent = text;
+      result.appendChild(el);
+      return el;
+    };
+    let debts;
+    try {
+      debts = settle(parsePlayers(new URLSearchParams(body)));
+    } catch (err) {
+      line(err.message);
+      return;
+    }
+    Object.keys(debts).sort(compareNames).forEach((debtor) => {
+      debts[debtor].forEach((debt) => {
+        const el = line(message("owes", {debtor: debtor, amount: amountFormat.format(debt.amount / scale), creditor: debt.creditor}), debt.paid ? "s" : "div");
+        if (debt.unmatched) {
+          el.textContent += " (" + message("unmatched") + ")";
+        }
+      });
+    });
+  };
+
+  // normalizeName and splitNames port the functions of the players package.
+  const normalizeName = (name) => name.trim().split(/\s+/).join(" ").normalize("NFC");
+  const splitNames = (list) => (list || "").split(/[\n\r,;]/).map(normalizeName).filter((name) => name !== "");
+
+  // compareNames compares the names like lessName() in the players package:
+  // ignoring case and accents, then by their code units.
+  const compareNames = (a, b) => collator.compare(a, b) || (a < b ? -1 : a > b ? 1 : 0);
+
+  // parseAmount parses an amount like the server does, see parseAmount() in
+  // the players package. It throws an error if the amount is invalid.
+  const parseAmount = (s) => {
+    const invalid = new Error(message("invalid"));
+    s = (s || "").trim().replace(/['’ \u00a0\u202f]/g, "");
+    if (s === "") {
+      return 0;
+    }
+    const ungroup = (digits, group) => {
+      const groups = digits.split(group);
+      if (groups.slice(1).some((g) => g.length !== 3)) {
+        throw invalid;
+      }
+      return groups.join("");
+    };
+    let sep = "";
+    const lastComma = s.lastIndexOf(","), lastPeriod = s.lastIndexOf(".");
+    if (lastComma >= 0 && lastPeriod >= 0) {
+      sep = lastPeriod > lastComma ? "." : ",";
+      const i = s.lastIndexOf(sep);
+      s = ungroup(s.slice(0, i), sep === "." ? "," : ".") + s.slice(i);
+    } else if (lastComma >= 0 || lastPeriod >= 0) {
+      sep = lastPeriod >= 0 ? "." : ",";
+      if (s.split(sep).length > 2 || (sep !== decimalSep && s.length - s.indexOf(sep) - 1 === 3)) {
+        s = ungroup(s, sep);
+        sep = "";
+      }
+    }
+    if (sep !== "") {
+      s = s.replace(sep, ".");
+    }
+    const m = /^(\d*)(?:\.(\d*))?$/.exec(s);
+    if (!m || (m[1] === "" && !m[2])) {
+      throw invalid;
+    }
+    const decimals = (m[2] || "").replace(/0+$/, "");
+    if (decimals.length > 4) {
+      throw invalid;
+    }
+    return Number(m[1] || "0") * scale + Number(decimals.padEnd(4, "0"));
+  };
+
+  // moneyString formats an amount like Money.String() in the players package,
+  // e.g. "12.50", to match the debts marked as paid.
+  const moneyString = (amount) => {
+    const decimals = String(amount % scale).padStart(4, "0").replace(/0+$/, "").padEnd(2, "0");
+    return Math.floor(amount / scale) + "." + decimals;
+  };
+
+  // parsePlayers returns the players submitted in the form, like FromForm()
+  // in the players package, along with the players added by name.
+  const parsePlayers = (params) => {
+    const players = [];
+    params.forEach((value, key) => {
+      const m = /^player(.*)$/.exec(key);
+      if (!m || value.trim() === "") {
+        return;
+      }
+      const i = m[1];
+      players.push({
+        name: normalizeName(value),
+        buyIn: parseAmount(params.get("buyin" + i)),
+        stack: parseAmount(params.get("stack" + i)),
+        methods: params.getAll("methods" + i),
+        avoids: params.getAll("avoids" + i).map(normalizeName),
+        prefers: params.getAll("prefers" + i).map(normalizeName),
+        paid: params.getAll("paid" + i),
+      });
+    });
+    splitNames(params.get("names")).forEach((name) => {
+      players.push({name: name, buyIn: 0, stack: 0, methods: [], avoids: [], prefers: [], paid: []});
+    });
+    return players;
+  };
+
+  const balance = (p) => p.stack - p.buyIn;
+  const sum = (players, f) => players.reduce((total, p) => total + f(p), 0);
+  const avoids = (p, o) => p.avoids.includes(o.name) || o.avoids.includes(p.name);
+  const prefers = (p, o) => p.prefers.includes(o.name) || o.prefers.includes(p.name);
+  const shares = (p, o) => p.methods.length === 0 || o.methods.length === 0 || p.methods.some((m) => o.methods.includes(m));
+
+  // rank ports rank() of the players package.
+  const rank = (looser, winner) => {
+    const s = shares(looser, winner), p = prefers(looser, winner);
+    return s && p ? 0 : s ? 1 : p ? 2 : 3;
+  };
+
+  // byBalance ports byBalance() of the players package.
+  const byBalance = (players) =>
+    players.filter((p) => balance(p) !== 0).sort((a, b) => balance(b) - balance(a) || compareNames(a.name, b.name));
+
+  // unsettleable ports unsettleable() of the players package: it returns the
+  // loosers who can't pay their debts without paying winners they avoid,
+  // using the Edmonds-Karp algorithm.
+  const unsettleable = (winners, loosers) => {
+    const total = sum(loosers, (l) => -balance(l));
+    const source = 0, sink = loosers.length + winners.length + 1;
+    const winner = (j) => 1 + loosers.length + j;
+    const residual = [];
+    for (let i = 0; i <= sink; i++) {
+      residual.push(new Array(sink + 1).fill(0));
+    }
+    loosers.forEach((l, i) => {
+      residual[source][1 + i] = -balance(l);
+      winners.forEach((w, j) => {
+        if (!avoids(l, w)) {
+          residual[1 + i][winner(j)] = total;
+        }
+      });
+    });
+    winners.forEach((w, j) => {
+      residual[winner(j)][sink] = balance(w);
+    });
+    for (;;) {
+      const prev = new Array(sink + 1).fill(-1);
+      prev[source] = source;
+      const queue = [source];
+      while (queue.length > 0) {
+        const u = queue.shift();
+        residual[u].forEach((c, v) => {
+          if (prev[v] < 0 && c > 0) {
+            prev[v] = u;
+            queue.push(v);
+          }
+        });
+      }
+      if (prev[sink] < 0) {
+        return loosers.filter((l, i) => prev[1 + i] >= 0);
+      }
+      let bottleneck = total;
+      for (let v = sink; v !== source; v = prev[v]) {
+        bottleneck = Math.min(bottleneck, residual[prev[v]][v]);
+      }
+      for (let v = sink; v !== source; v = prev[v]) {
+        residual[prev[v]][v] -= bottleneck;
+        residual[v][prev[v]] += bottleneck;
+      }
+    }
+  };
+
+  // next ports next() of the players package.
+  const next = (winners, loosers) => {
+    for (const l of byBalance(loosers)) {
+      const candidates = byBalance(winners);
+      // Array.prototype.sort() is stable, like sort.SliceStable().
+      candidates.sort((a, b) => rank(l, a) - rank(l, b));
+      for (const w of candidates) {
+        if (avoids(l, w)) {
+          continue;
+        }
+        const looserStack = l.stack, winnerStack = w.stack;
+        const amount = Math.min(-balance(l), balance(w));
+        l.stack += amount;
+        w.stack -= amount;
+        const stuck = unsettleable(winners, loosers);
+        l.stack = looserStack;
+        w.stack = winnerStack;
+        if (stuck.length === 0) {
+          return [l, w];
+        }
+      }
+    }
+    throw new Error("no debt can be settled");
+  };
+
+  // settle ports Players.CalculateDebts() of the players package: it returns
+  // the debts of each debtor, without rounding them.
+  const settle = (players) => {
+    if (sum(players, (p) => p.buyIn) !== sum(players, (p) => p.stack)) {
+      throw new Error(message("mismatch"));
+    }
+    const winners = [], loosers = [];
+    players.slice().sort((a, b) => compareNames(a.name, b.name)).forEach((p) => {
+      const clone = Object.assign({}, p);
+      (clone.stack >= clone.buyIn ? winners : loosers).push(clone);
+    });
+    const stuck = unsettleable(winners, loosers);
+    if (stuck.length > 0) {
+      throw new Error(message("stuck", {players: stuck.map((p) => p.name).join(", ")}));
+    }
+    const debts = {};
+    while (sum(winners, (p) => p.buyIn) !== sum(winners, (p) => p.stack)) {
+      const [looser, winner] = next(winners, loosers);
+      const amount = Math.min(-balance(looser), balance(winner));
+      looser.stack += amount;
+      winner.stack -= amount;
+      (debts[looser.name] = debts[looser.name] || []).push({
+        creditor: winner.name,
+        amount: amount,
+        unmatched: !shares(looser, winner),
+        paid: looser.paid.includes(winner.name + ": " + moneyString(amount)),
+      });
+    }
+    Object.keys(debts).forEach((debtor) => {
+      debts[debtor].sort((a, b) => b.amount - a.amount || compareNames(a.creditor, b.creditor));
+    });
+    return debts;
+  };
+
+  form.addEventListener("submit", (event) => {
+    event.preventDefault();
+    const body = new URLSearchParams(new FormData(form)).toString();
+    submit(body).catch(() => {
+      localStorage.setItem(pendingKey, body);
+      showOffline(body);
+    });
+  });
+  window.addEventListener("online", sync);
+  sync();
+})();
@@ -0,0 +1,83 @@
+package pokersplit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestStaticPath(t *testing.T) {
+	for _, name := range []string{"pokersplit.css", "offline.js"} {
+		got, err := staticPath(name)
+		if err != nil {
+			t.Errorf("staticPath(%q) returned an error: %v", name, err)
+			continue
+		}
+		ext := name[strings.LastIndex(name, "."):]
+		want := regexp.MustCompile(`^/static/` + regexp.QuoteMeta(strings.TrimSuffix(name, ext)) + `\.[0-9a-f]{10}` + regexp.QuoteMeta(ext) + `$`)
+		if !want.MatchString(got) {
+			t.Errorf("staticPath(%q) = %q, want a match of %q", name, got, want)
+		}
+	}
+	if _, err := staticPath("unknown.css"); err == nil {
+		t.Errorf("staticPath() of an unknown asset didn't return an error, but one was expected")
+	}
+}
+
+func TestServeStatic(t *testing.T) {
+	path, err := staticPath("pokersplit.css")
+	if err != nil {
+		t.Fatalf("staticPath() returned an error: %v", err)
+	}
+	w := httptest.NewRecorder()
+	ServeStatic(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("ServeStatic(%q) status = %d, want %d", path, w.Code, http.StatusOK)
+	}
+	if got, want := w.Header().Get("Content-Type"), "text/css; charset=utf-8"; got != want {
+		t.Errorf("ServeStatic(%q) Content-Type = %q, want %q", path, got, want)
+	}
+	if got := w.Header().Get("Cache-Control"); !strings.Contains(got, "immutable") {
+		t.Errorf("ServeStatic(%q) Cache-Control = %q, want it to be immutable", path, got)
+	}
+
+	for _, p := range []string{"/static/pokersplit.css", "/static/pokersplit.0000000000.css"} {
+		w := httptest.NewRecorder()
+		ServeStatic(w, httptest.NewRequest(http.MethodGet, p, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("ServeStatic(%q) status = %d, want %d", p, w.Code, http.StatusNotFound)
+		}
+	}
+}
+
+// TestServiceWorkerAssets tests that the service worker caches the static
+// assets by their current path.
+func TestServiceWorkerAssets(t *testing.T) {
+	for name, a := range assets {
+		if !strings.Contains(serviceWorker, `"`+a.path+`"`) {
+			t.Errorf("the service worker doesn't cache %q by its path %q", name, a.path)
+		}
+	}
+	if strings.Contains(serviceWorker, "{{") {
+		t.Errorf("the service worker wasn't fully rendered:\n%s", serviceWorker)
+	}
+}
+
+// TestStylesheetClasses tests that the stylesheet defines all the classes
+// the templates use.
+func TestStylesheetClasses(t *testing.T) {
+	css := string(assets["pokersplit.css"].content)
+	classAttr := regexp.MustCompile(`class="([^"]*)"`)
+	action := regexp.MustCompile(`{{[^}]*}}`)
+	for name, page := range map[string]string{"index": index, "merge": merge, "new": newPage, "history": history, "net": netPage} {
+		for _, m := range classAttr.FindAllStringSubmatch(page, -1) {
+			for _, class := range strings.Fields(action.ReplaceAllString(m[1], " ")) {
+				if !regexp.MustCompile(`\.` + regexp.QuoteMeta(class) + `[\s,{:.\[>]`).MatchString(css) {
+					t.Errorf("the stylesheet doesn't define the class %q used by the %s template", class, name)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package pokersplit
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+)
+
+// storeSize is the number of games the store keeps track of. The games which
+// weren't saved nor loaded for the longest time are forgotten first.
+const storeSize = 1000
+
+// store keeps track of the latest revision of the games saved since the server
+// started, up to a maximum number of games. The games are still fully encoded
+// in their URL, the store is only used to detect concurrent edits: once a game
+// is forgotten, its next edit is accepted as is.
+type store struct {
+	mu   sync.Mutex
+	size int
+	// recent holds the games, the most recently used first, and games maps
+	// the IDs of the games to their element.
+	recent *list.List
+	games  map[string]*list.Element
+}
+
+// newStore returns a store keeping track of at most size games.
+func newStore(size int) *store {
+	return &store{size: size, recent: list.New(), games: make(map[string]*list.Element)}
+}
+
+// commit saves g, which was derived from the given revision of the same game.
+// If another revision of the game was saved since then, g is discarded and the
+// latest revision of the game is returned along with false.
+func (s *store) commit(g *players.Game, revision int) (*players.Game, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.games[g.ID]; ok {
+		s.recent.MoveToFront(e)
+		if latest := e.Value.(*players.Game); latest.Revision != revision {
+			return latest, false
+		}
+		e.Value = g
+		return g, true
+	}
+	s.games[g.ID] = s.recent.PushFront(g)
+	if s.recent.Len() > s.size {
+		oldest := s.recent.Remove(s.recent.Back()).(*players.Game)
+		delete(s.games, oldest.ID)
+	}
+	return g, true
+}
+
+// get returns the latest revision of the game with the given ID, if it was
+// saved since the server started and wasn't forgotten since then.
+func (s *store) get(id string) (*players.Game, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.games[id]
+	if !ok {
+		return nil, false
+	}
+	s.recent.MoveToFront(e)
+	return e.Value.(*players.Game), true
+}
@@ -0,0 +1,56 @@
+package pokersplit
+
+import (
+	"testing"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+)
+
+func TestStoreCommit(t *testing.T) {
+	s := newStore(10)
+	if _, ok := s.commit(&players.Game{ID: "a", Revision: 1}, 0); !ok {
+		t.Fatalf("store.commit() of a new game failed")
+	}
+	if _, ok := s.commit(&players.Game{ID: "a", Revision: 2}, 1); !ok {
+		t.Fatalf("store.commit() of the next revision failed")
+	}
+	if _, ok := s.commit(&players.Game{ID: "b", Revision: 6}, 5); !ok {
+		t.Fatalf("store.commit() of another game failed")
+	}
+
+	// Someone else edited revision 1, which was already superseded.
+	latest, ok := s.commit(&players.Game{ID: "a", Revision: 2}, 1)
+	if ok {
+		t.Fatalf("store.commit() of a concurrent edit succeeded, but should have failed")
+	}
+	if latest.Revision != 2 {
+		t.Errorf("store.commit() returned revision %d as latest, want 2", latest.Revision)
+	}
+}
+
+func TestStoreSize(t *testing.T) {
+	s := newStore(2)
+	s.commit(&players.Game{ID: "a", Revision: 1}, 0)
+	s.commit(&players.Game{ID: "b", Revision: 1}, 0)
+	// Loading "a" makes "b" the least recently used game.
+	s.get("a")
+	s.commit(&players.Game{ID: "c", Revision: 1}, 0)
+
+	for _, c := range []struct {
+		id   string
+		want bool
+	}{
+		{id: "a", want: true},
+		{id: "b", want: false},
+		{id: "c", want: true},
+	} {
+		if _, ok := s.get(c.id); ok != c.want {
+			t.Errorf("store.get(%q) found the game: %t, want %t", c.id, ok, c.want)
+		}
+	}
+
+	// The edits of a forgotten game are accepted as is.
+	if _, ok := s.commit(&players.Game{ID: "b", Revision: 3}, 2); !ok {
+		t.Errorf("store.commit() of a forgotten game failed")
+	}
+}
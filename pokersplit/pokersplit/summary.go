@@ -0,0 +1,148 @@
+package pokersplit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+)
+
+// summaryOptions configures how the summary of a game is formatted.
+type summaryOptions struct {
+	// Markdown formats the summary in Markdown instead of plain text.
+	Markdown bool
+	// Emoji decorates the summary with emojis.
+	Emoji bool
+}
+
+// writeSummary writes a summary of the game meant to be pasted in a group
+// chat: the net result of each player, from the biggest winner to the biggest
+// looser, followed by the debts.
+func writeSummary(w io.Writer, p players.Players, debts players.Debts, l *locale, opts summaryOptions) {
+	heading := func(s string) {
+		if opts.Markdown {
+			s = "**" + s + "**"
+		}
+		fmt.Fprintln(w, s)
+	}
+	item := func(s string) {
+		if opts.Markdown {
+			s = "- " + s
+		}
+		fmt.Fprintln(w, s)
+	}
+	bold := func(s string) string {
+		if opts.Markdown {
+			return "**" + markdownEscaper.Replace(s) + "**"
+		}
+		return s
+	}
+	emoji := func(e string) string {
+		if opts.Emoji {
+			return e + " "
+		}
+		return ""
+	}
+
+	heading(emoji("🃏") + l.p.Sprintf("PokerSplit result"))
+	fmt.Fprintln(w)
+	heading(l.p.Sprintf("Net results"))
+	byNet := sorted(p, l.tag)
+	sort.SliceStable(byNet, func(i, j int) bool {
+		return byNet[i].Net().Cmp(byNet[j].Net()) > 0
+	})
+	for _, player := range byNet {
+		e := "⚪"
+		switch player.Net().Sign() {
+		case 1:
+			e = "🟢"
+		case -1:
+			e = "🔴"
+		}
+		item(emoji(e) + bold(player.Name) + ": " + l.signed(player.Net()))
+	}
+
+	fmt.Fprintln(w)
+	heading(l.p.Sprintf("Who owes whom"))
+	if len(debts) == 0 {
+		item(l.p.Sprintf("Nobody owes anything."))
+	}
+	for _, debtor := range debts.Debtors(l.tag) {
+		for _, d := range debts[debtor] {
+			e, paid := "💸", ""
+			if d.Paid {
+				e, paid = "✅", " "+l.p.Sprintf("(paid)")
+			}
+			item(emoji(e) + l.p.Sprintf("%s owes %s to %s", bold(debtor), l.amount(d.Amount), bold(d.Creditor)) + paid)
+		}
+	}
+}
+
+// markdownEscaper escapes the characters of players' names which have a
+// meaning in Markdown.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`)
+
+// summary returns the plain-text summary of the game.
+func summary(p players.Players, debts players.Debts, l *locale) string {
+	var buf bytes.Buffer
+	writeSummary(&buf, p, debts, l, summaryOptions{})
+	return buf.String()
+}
+
+// ServeSummary writes the summary of the game encoded in the URL path, after
+// the "/summary/" prefix. The "format" URL parameter can be set to "markdown"
+// to get it in Markdown instead of plain text, and the "emoji" one to "1" to
+// decorate it with emojis. The debts are rounded according to the "unit" URL
+// parameter, like on the game's page.
+func ServeSummary(w http.ResponseWriter, r *http.Request) {
+	l := negotiate(w, r)
+	if r.Method != http.MethodGet {
+		http.Error(w, l.errorf("unsupported HTTP method: %s", r.Method).Error(), http.StatusMethodNotAllowed)
+		return
+	}
+	g, err := players.GameFromBase64(strings.TrimPrefix(r.URL.Path, "/summary/"))
+	if err != nil {
+		http.Error(w, l.errorf("failed to decode players: %v", err).Error(), http.StatusBadRequest)
+		return
+	}
+	s, err := settle(g.Players, r, l)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	opts := summaryOptions{Emoji: q.Get("emoji") == "1"}
+	switch format := q.Get("format"); format {
+	case "", "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	case "markdown":
+		opts.Markdown = true
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	default:
+		http.Error(w, l.errorf("unsupported format: %q", format).Error(), http.StatusBadRequest)
+		return
+	}
+	writeSummary(w, g.Players, s.Debts, l, opts)
+}
+
+// settle settles the debts of the players, rounding them according to the
+// "unit" URL parameter of the request, like on the game's page. The returned
+// error is translated.
+func settle(p players.Players, r *http.Request, l *locale) (*players.Settlement, error) {
+	var opts players.SettleOptions
+	if unit := r.URL.Query().Get("unit"); unit != "" {
+		var err error
+		if opts.Unit, err = players.ParseMoney(unit); err != nil {
+			return nil, l.errorf("invalid rounding unit: %v", err)
+		}
+	}
+	s, err := p.Settle(opts)
+	if err != nil {
+		return nil, l.errorf("failed to calculate debts: %v", err)
+	}
+	return s, nil
+}
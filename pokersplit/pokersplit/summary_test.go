@@ -0,0 +1,72 @@
+package pokersplit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fhchstr/pokersplit/pokersplit/players"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/text/language"
+)
+
+func TestWriteSummary(t *testing.T) {
+	p := players.Players{
+		{Name: "bob", BuyIn: players.Cents(1000), Stack: players.Cents(0)},
+		{Name: "alice", BuyIn: players.Cents(1000), Stack: players.Cents(250000)},
+		{Name: "d_ave", BuyIn: players.Cents(1000), Stack: players.Cents(1000)},
+		{Name: "charlie", BuyIn: players.Cents(250000), Stack: players.Cents(12000)},
+	}
+	debts := players.Debts{
+		"bob":     {{Creditor: "alice", Amount: players.Cents(1000), Paid: true}},
+		"charlie": {{Creditor: "alice", Amount: players.Cents(238000)}},
+	}
+	cases := []struct {
+		desc string
+		tag  language.Tag
+		opts summaryOptions
+		want string
+	}{
+		{
+			desc: "text",
+			tag:  language.English,
+			want: `PokerSplit result
+
+Net results
+alice: +2,490.00
+d_ave: 0.00
+bob: -10.00
+charlie: -2,380.00
+
+Who owes whom
+bob owes 10.00 to alice (paid)
+charlie owes 2,380.00 to alice
+`,
+		},
+		{
+			desc: "markdown_with_emojis",
+			tag:  language.German,
+			opts: summaryOptions{Markdown: true, Emoji: true},
+			want: `**🃏 PokerSplit-Ergebnis**
+
+**Nettoergebnisse**
+- 🟢 **alice**: +2.490,00
+- ⚪ **d\_ave**: 0,00
+- 🔴 **bob**: -10,00
+- 🔴 **charlie**: -2.380,00
+
+**Wer schuldet wem**
+- ✅ **bob** schuldet **alice** 10,00 (bezahlt)
+- 💸 **charlie** schuldet **alice** 2.380,00
+`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeSummary(&buf, p, debts, newLocale(c.tag), c.opts)
+			if diff := cmp.Diff(c.want, buf.String()); diff != "" {
+				t.Errorf("writeSummary() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
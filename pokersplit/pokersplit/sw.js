@@ -0,0 +1,53 @@
+// sw.js is the service worker of PokerSplit, rendered by the server as a
+// text/template. It caches the pages and their assets, so that the games can
+// still be opened without network access.
+"use strict";
+
+const cacheName = "pokersplit-{{.Version}}";
+
+// assets are cached as soon as the service worker is installed. The paths of
+// the static assets are filled in by the server.
+const assets = [
+  "/",
+  "/manifest.webmanifest",
+  "/icon.svg",
+{{- range .Assets}}
+  "{{.}}",
+{{- end}}
+];
+
+self.addEventListener("install", (event) => {
+  event.waitUntil(
+    caches.open(cacheName)
+      .then((cache) => cache.addAll(assets))
+      .then(() => self.skipWaiting())
+  );
+});
+
+self.addEventListener("activate", (event) => {
+  event.waitUntil(
+    caches.keys()
+      .then((keys) => Promise.all(keys.filter((key) => key !== cacheName).map((key) => caches.delete(key))))
+      .then(() => self.clients.claim())
+  );
+});
+
+// The network is tried first, so that the latest revision of the games is
+// displayed, and the cache is used when it can't be reached. The forms aren't
+// cached: offline.js keeps them until they can be submitted.
+self.addEventListener("fetch", (event) => {
+  if (event.request.method !== "GET") {
+    return;
+  }
+  event.respondWith(
+    fetch(event.request)
+      .then((response) => {
+        if (response.ok) {
+          const copy = response.clone();
+          caches.open(cacheName).then((cache) => cache.put(event.request, copy));
+        }
+        return response;
+      })
+      .catch(() => caches.match(event.request).then((response) => response || Response.error()))
+  );
+});